@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var errUnsupportedOnWindows = errors.New("not supported on Windows")
+
+// jobState tracks whether a background job is still running or has been
+// suspended with Ctrl-Z.
+type jobState int
+
+const (
+	jobRunning jobState = iota
+	jobStopped
+	jobDone
+)
+
+func (s jobState) String() string {
+	switch s {
+	case jobRunning:
+		return "Running"
+	case jobStopped:
+		return "Stopped"
+	default:
+		return "Done"
+	}
+}
+
+// job is one entry in the jobs table: a single external command started in
+// the background (or foreground, while it's still running).
+type job struct {
+	id      int
+	cmd     *exec.Cmd
+	command string
+	state   jobState
+	done    chan struct{}
+	// stopped receives a signal when Ctrl-Z has suspended this job, so the
+	// exec handler waiting on it can hand control back to the prompt.
+	stopped chan struct{}
+	// exitErr is cmd.Wait()'s result, set by reapJob just before it closes
+	// done. Only meaningful once done is closed.
+	exitErr error
+}
+
+// jobTable tracks every job started by the shell, keyed by job ID (the
+// number used in "%N").
+var jobTable = struct {
+	mu     sync.Mutex
+	jobs   []*job
+	nextID int
+}{nextID: 1}
+
+// registerJob adds a newly started command to the jobs table and returns
+// its job.
+func registerJob(cmd *exec.Cmd, command string) *job {
+	jobTable.mu.Lock()
+	defer jobTable.mu.Unlock()
+
+	j := &job{id: jobTable.nextID, cmd: cmd, command: command, state: jobRunning, done: make(chan struct{}), stopped: make(chan struct{}, 1)}
+	jobTable.nextID++
+	jobTable.jobs = append(jobTable.jobs, j)
+	return j
+}
+
+// finishJob marks a job as done and removes it from the table.
+func finishJob(j *job) {
+	jobTable.mu.Lock()
+	defer jobTable.mu.Unlock()
+
+	j.state = jobDone
+	close(j.done)
+	for i, existing := range jobTable.jobs {
+		if existing == j {
+			jobTable.jobs = append(jobTable.jobs[:i], jobTable.jobs[i+1:]...)
+			break
+		}
+	}
+}
+
+// reapJob waits for j's underlying process to actually exit and finalizes
+// it exactly once when it does. It's the only goroutine that ever calls
+// cmd.Wait(), so a job can be stopped and resumed (Ctrl-Z/fg/bg) any
+// number of times without orphaning the wait or leaving the job stuck
+// "Running" in the jobs table after it's gone.
+func reapJob(j *job) {
+	j.exitErr = j.cmd.Wait()
+	finishJob(j)
+}
+
+// waitForeground blocks until job j exits or is stopped (Ctrl-Z),
+// interrupting it if ctx is cancelled in the meantime (Ctrl-C itself
+// reaches the job through signalForeground, not through ctx). It's used
+// both to wait on a freshly started external command and by fg to wait on
+// one it just resumed. exited reports whether the job actually finished;
+// if so, err is its exit error (nil on success).
+func waitForeground(ctx context.Context, j *job) (exited bool, err error) {
+	setForeground(j)
+	defer clearForeground(j)
+
+	for {
+		select {
+		case <-j.done:
+			return true, j.exitErr
+		case <-ctx.Done():
+			interruptJob(j.cmd)
+		case <-j.stopped:
+			fmt.Printf("\n[%d]+  Stopped  %s\n", j.id, j.command)
+			return false, nil
+		}
+	}
+}
+
+// findJob looks up a job by its "%N" or bare "N" spec. With no spec, it
+// returns the most recently started job, matching fg/bg with no argument.
+func findJob(spec string) (*job, error) {
+	jobTable.mu.Lock()
+	defer jobTable.mu.Unlock()
+
+	if len(jobTable.jobs) == 0 {
+		return nil, errors.New("no current job")
+	}
+
+	if spec == "" {
+		return jobTable.jobs[len(jobTable.jobs)-1], nil
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(spec, "%"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid job spec: %s", spec)
+	}
+
+	for _, j := range jobTable.jobs {
+		if j.id == id {
+			return j, nil
+		}
+	}
+	return nil, fmt.Errorf("no such job: %s", spec)
+}
+
+// jobsBuiltin implements the `jobs` command: list background jobs and
+// their state.
+func jobsBuiltin() error {
+	jobTable.mu.Lock()
+	defer jobTable.mu.Unlock()
+
+	for _, j := range jobTable.jobs {
+		fmt.Printf("[%d]  %-8s %s\n", j.id, j.state, j.command)
+	}
+	return nil
+}
+
+// fgBuiltin implements `fg [%N]`: resume a stopped job (if any) and wait
+// for it to finish in the foreground. It can be Ctrl-Z'd again itself, in
+// which case it returns to the prompt the same way a freshly started job
+// does.
+func fgBuiltin(ctx context.Context, spec string) error {
+	j, err := findJob(spec)
+	if err != nil {
+		return err
+	}
+
+	if j.state == jobStopped {
+		if err := continueJob(j.cmd); err != nil {
+			return fmt.Errorf("failed to resume job: %w", err)
+		}
+		j.state = jobRunning
+	}
+
+	fmt.Println(j.command)
+	waitForeground(ctx, j)
+	return nil
+}
+
+// bgBuiltin implements `bg [%N]`: resume a stopped job without waiting for
+// it.
+func bgBuiltin(spec string) error {
+	j, err := findJob(spec)
+	if err != nil {
+		return err
+	}
+	if j.state != jobStopped {
+		return fmt.Errorf("job %d is not stopped", j.id)
+	}
+	if err := continueJob(j.cmd); err != nil {
+		return fmt.Errorf("failed to resume job: %w", err)
+	}
+	j.state = jobRunning
+	fmt.Printf("[%d]  %s &\n", j.id, j.command)
+	return nil
+}
+
+// killJobBuiltin implements `kill %N`: signal a backgrounded job, as
+// opposed to `kill <pid>` which is left to the real `kill` binary.
+func killJobBuiltin(spec string) error {
+	j, err := findJob(spec)
+	if err != nil {
+		return err
+	}
+	return interruptJob(j.cmd)
+}
+
+// foreground is the job currently running synchronously, i.e. the one
+// Ctrl-C/Ctrl-Z should act on. It's nil while the shell is just sitting at
+// the prompt.
+var foreground = struct {
+	mu  sync.Mutex
+	job *job
+}{}
+
+func setForeground(j *job) {
+	foreground.mu.Lock()
+	foreground.job = j
+	foreground.mu.Unlock()
+}
+
+func clearForeground(j *job) {
+	foreground.mu.Lock()
+	if foreground.job == j {
+		foreground.job = nil
+	}
+	foreground.mu.Unlock()
+}
+
+// signalForeground delivers a signal to the job currently in the
+// foreground, if any. Called from the SIGINT/SIGTSTP handler.
+func signalForeground(stop bool) {
+	foreground.mu.Lock()
+	j := foreground.job
+	foreground.mu.Unlock()
+	if j == nil {
+		return
+	}
+
+	if stop {
+		if err := stopJob(j.cmd); err == nil {
+			j.state = jobStopped
+			select {
+			case j.stopped <- struct{}{}:
+			default:
+			}
+		}
+		return
+	}
+
+	interruptJob(j.cmd)
+}
@@ -0,0 +1,52 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+)
+
+// newProcAttr returns the SysProcAttr used for commands we run, putting
+// each one in its own process group so job control (fg/bg/kill %N) and
+// Ctrl-C only ever affect the job it's aimed at.
+func newProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setpgid: true}
+}
+
+// signalJob delivers sig to every process in cmd's process group.
+func signalJob(cmd *exec.Cmd, sig syscall.Signal) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return syscall.Kill(-cmd.Process.Pid, sig)
+}
+
+// stopJob suspends a running job, as Ctrl-Z would.
+func stopJob(cmd *exec.Cmd) error {
+	return signalJob(cmd, syscall.SIGSTOP)
+}
+
+// continueJob resumes a stopped job.
+func continueJob(cmd *exec.Cmd) error {
+	return signalJob(cmd, syscall.SIGCONT)
+}
+
+// interruptJob sends Ctrl-C's signal to a job.
+func interruptJob(cmd *exec.Cmd) error {
+	return signalJob(cmd, syscall.SIGINT)
+}
+
+// watchJobSignals forwards Ctrl-C and Ctrl-Z from the terminal to whichever
+// job is currently in the foreground, instead of killing the shell itself.
+func watchJobSignals() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTSTP)
+	go func() {
+		for sig := range sigCh {
+			signalForeground(sig == syscall.SIGTSTP)
+		}
+	}()
+}
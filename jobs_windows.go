@@ -0,0 +1,55 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+)
+
+// newProcAttr returns the SysProcAttr used for commands we run. Windows has
+// no process-group equivalent of Unix's Setpgid, so jobs are only tracked,
+// not grouped.
+func newProcAttr() *syscall.SysProcAttr {
+	return nil
+}
+
+// signalJob is a best-effort stand-in for Unix signal delivery: Windows
+// processes can't be signaled, so this always just kills them.
+func signalJob(cmd *exec.Cmd, sig syscall.Signal) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return cmd.Process.Kill()
+}
+
+// stopJob can't suspend a process on Windows.
+func stopJob(cmd *exec.Cmd) error {
+	return errUnsupportedOnWindows
+}
+
+// continueJob can't resume a process on Windows.
+func continueJob(cmd *exec.Cmd) error {
+	return errUnsupportedOnWindows
+}
+
+// interruptJob kills the job; Windows has no SIGINT to deliver to another
+// process.
+func interruptJob(cmd *exec.Cmd) error {
+	return signalJob(cmd, syscall.SIGINT)
+}
+
+// watchJobSignals forwards Ctrl-C to whichever job is currently in the
+// foreground. Windows has no Ctrl-Z/SIGTSTP equivalent, so only SIGINT is
+// handled.
+func watchJobSignals() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	go func() {
+		for range sigCh {
+			signalForeground(false)
+		}
+	}()
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/chzyer/readline"
+
+	"starshell/star"
+)
+
+// historyFileName is the same kind of dotfile as .starshellrc: a single
+// per-user file in the home directory.
+const historyFileName = ".starshell_history"
+
+func historyPath() string {
+	return filepath.Join(getHomeDirectory(), historyFileName)
+}
+
+// shellBuiltins completes the command position alongside whatever's on
+// PATH, since they aren't real files a PATH scan would ever find.
+var shellBuiltins = []string{"cd", "ls", "dir", "clear", "cls", "star", "jobs", "fg", "bg", "kill", "exit", "alias"}
+
+// starSubcommands completes the second word of a `star ...` invocation.
+var starSubcommands = []string{"install", "list", "uninstall", "update", "why"}
+
+// starPackageVerbs are the `star` subcommands whose argument is an
+// already-installed user/repo, as opposed to `install`, whose argument
+// isn't known locally yet.
+var starPackageVerbs = map[string]bool{"uninstall": true, "update": true, "why": true}
+
+// Completer produces candidate completions for one kind of word. The
+// editor tries each registered Completer in turn and uses the first one
+// that returns anything, so new sources (e.g. a GitHub search API for
+// `star install <TAB>`) can be added later without touching the others.
+type Completer interface {
+	// Complete returns full replacement candidates for prefix, the word
+	// at position wordIndex in the line (0 is the command itself).
+	// words holds the already-completed words before it. Implementations
+	// that don't apply to this wordIndex/words combination return nil.
+	Complete(words []string, wordIndex int, prefix string) []string
+}
+
+type binaryCompleter struct{}
+
+func (binaryCompleter) Complete(words []string, wordIndex int, prefix string) []string {
+	if wordIndex != 0 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var out []string
+	add := func(name string) {
+		if seen[name] || !strings.HasPrefix(name, prefix) {
+			return
+		}
+		seen[name] = true
+		out = append(out, name)
+	}
+	for _, name := range shellBuiltins {
+		add(name)
+	}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				add(entry.Name())
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+type subcommandCompleter struct{}
+
+func (subcommandCompleter) Complete(words []string, wordIndex int, prefix string) []string {
+	if wordIndex != 1 || len(words) < 1 || words[0] != "star" {
+		return nil
+	}
+	var out []string
+	for _, name := range starSubcommands {
+		if strings.HasPrefix(name, prefix) {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// starPackageCompleter completes `star uninstall|update|why <TAB>` from the
+// packages already recorded in the package DB.
+type starPackageCompleter struct{}
+
+func (starPackageCompleter) Complete(words []string, wordIndex int, prefix string) []string {
+	if wordIndex != 2 || len(words) < 2 || words[0] != "star" || !starPackageVerbs[words[1]] {
+		return nil
+	}
+	installed, err := star.ListInstalledStars()
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, pkg := range installed {
+		name := pkg.User + "/" + pkg.Repo
+		if strings.HasPrefix(name, prefix) {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// filesystemCompleter is the fallback for any word that isn't a command,
+// a `star` subcommand or an installed package: a plain path completion.
+// Directories get a trailing "/", the same hint ls/bash use.
+//
+// Known gap: this does not apply the getFileColor palette to the completion
+// menu. chzyer/readline's AutoCompleter has no separate "display text" for a
+// candidate - whatever Complete returns here is exactly what gets inserted
+// into the line, so coloring the menu would mean splicing ANSI escape codes
+// into the command itself. Revisit if/when the editor grows a custom
+// renderer that can decouple the two.
+type filesystemCompleter struct{}
+
+func (filesystemCompleter) Complete(words []string, wordIndex int, prefix string) []string {
+	dir, partial := filepath.Split(prefix)
+	base := dir
+	if base == "" {
+		base = "."
+	}
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), partial) {
+			continue
+		}
+		candidate := dir + entry.Name()
+		if entry.IsDir() {
+			candidate += "/"
+		}
+		out = append(out, candidate)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// completers is tried in order for every TAB press.
+var completers = []Completer{subcommandCompleter{}, starPackageCompleter{}, binaryCompleter{}, filesystemCompleter{}}
+
+// shellCompleter adapts the Completer chain to readline's AutoCompleter.
+type shellCompleter struct{}
+
+func (shellCompleter) Do(line []rune, pos int) ([][]rune, int) {
+	wordStart := pos
+	for wordStart > 0 && !unicode.IsSpace(line[wordStart-1]) {
+		wordStart--
+	}
+	prefix := string(line[wordStart:pos])
+	words := strings.Fields(string(line[:wordStart]))
+
+	for _, c := range completers {
+		candidates := c.Complete(words, len(words), prefix)
+		if len(candidates) == 0 {
+			continue
+		}
+		suffixes := make([][]rune, len(candidates))
+		for i, candidate := range candidates {
+			suffixes[i] = []rune(strings.TrimPrefix(candidate, prefix))
+		}
+		return suffixes, len(prefix)
+	}
+	return nil, 0
+}
+
+// suggestionHistory holds every line entered at the prompt (whether or not
+// the command succeeded), oldest first, used
+// to offer the most recent matching entry as an inline suggestion. It's
+// seeded from the history file at startup and kept in memory after that,
+// since the readline library itself doesn't expose its history for
+// prefix search.
+var suggestionHistory []string
+
+func loadSuggestionHistory() {
+	data, err := os.ReadFile(historyPath())
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			suggestionHistory = append(suggestionHistory, line)
+		}
+	}
+}
+
+func recordSuggestionHistory(line string) {
+	suggestionHistory = append(suggestionHistory, line)
+}
+
+// suggest returns the suffix to complete prefix with, taken from the most
+// recent history entry that starts with it.
+func suggest(prefix string) (string, bool) {
+	if prefix == "" {
+		return "", false
+	}
+	for i := len(suggestionHistory) - 1; i >= 0; i-- {
+		entry := suggestionHistory[i]
+		if entry != prefix && strings.HasPrefix(entry, prefix) {
+			return entry[len(prefix):], true
+		}
+	}
+	return "", false
+}
+
+// dimGray is dimmer than any of starshell.go's prompt/file colors, since
+// a suggestion is a hint, not something that's actually been typed.
+const dimGray = "\033[90m"
+
+// suggestionPainter draws the inline, fish-style autosuggestion after the
+// cursor without touching the real line buffer: Paint only changes what's
+// drawn, so accepting or rejecting the suggestion is just a matter of
+// whether the next keystroke asks for it.
+type suggestionPainter struct{}
+
+func (suggestionPainter) Paint(line []rune, pos int) []rune {
+	if pos != len(line) {
+		return line
+	}
+	suffix, ok := suggest(string(line))
+	if !ok {
+		return line
+	}
+	painted := make([]rune, 0, len(line)+len(suffix)+16)
+	painted = append(painted, line...)
+	painted = append(painted, []rune(dimGray)...)
+	painted = append(painted, []rune(suffix)...)
+	painted = append(painted, []rune(Reset)...)
+	painted = append(painted, []rune(fmt.Sprintf("\033[%dD", len([]rune(suffix))))...)
+	return painted
+}
+
+// acceptSuggestionListener accepts the painted suggestion with the
+// right-arrow key, but only when the cursor is already at the end of the
+// line - anywhere else, right-arrow should just move the cursor like
+// normal.
+type acceptSuggestionListener struct{}
+
+func (acceptSuggestionListener) OnChange(line []rune, pos int, key rune) (newLine []rune, newPos int, ok bool) {
+	if key != readline.CharForward || pos != len(line) {
+		return nil, 0, false
+	}
+	suffix, ok := suggest(string(line))
+	if !ok {
+		return nil, 0, false
+	}
+	newLine = append(append([]rune{}, line...), []rune(suffix)...)
+	return newLine, len(newLine), true
+}
+
+// newLineEditor builds the readline instance that drives the REPL's
+// input: persistent, de-duplicated history with Ctrl-R search (both
+// built into readline itself), tab completion and inline suggestions.
+func newLineEditor() (*readline.Instance, error) {
+	loadSuggestionHistory()
+	if len(suggestionHistory) > 0 {
+		lastHistoryLine = suggestionHistory[len(suggestionHistory)-1]
+	}
+
+	return readline.NewEx(&readline.Config{
+		HistoryFile:            historyPath(),
+		DisableAutoSaveHistory: true,
+		AutoComplete:           shellCompleter{},
+		Listener:               acceptSuggestionListener{},
+		Painter:                suggestionPainter{},
+	})
+}
+
+// lastHistoryLine is the most recently saved history entry, so saveLine
+// can skip writing consecutive duplicates - the rest of the de-duplication
+// (the same command re-submitted unmodified) is already handled by
+// readline itself.
+var lastHistoryLine string
+
+// saveLine records line in both the persistent, de-duplicated history and
+// the in-memory suggestion list.
+func saveLine(editor *readline.Instance, line string) {
+	if line != lastHistoryLine {
+		_ = editor.SaveHistory(line)
+		lastHistoryLine = line
+	}
+	recordSuggestionHistory(line)
+}
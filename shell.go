@@ -0,0 +1,372 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"mvdan.cc/sh/v3/expand"
+	"mvdan.cc/sh/v3/interp"
+	"mvdan.cc/sh/v3/syntax"
+
+	"starshell/star"
+)
+
+// newShellRunner builds the interp.Runner that drives the REPL: true
+// POSIX control flow (pipes, redirects, &&/||, subshells) and background
+// jobs are all handled by mvdan.cc/sh itself, while our own builtins (cd,
+// ls, clear, star, jobs/fg/bg/kill) are layered on top via CallHandler and
+// ExecHandlers so they keep working inside pipelines and substitutions.
+func newShellRunner() (*interp.Runner, error) {
+	// jobControlCallHandler needs the *Runner itself (to keep its notion
+	// of the working directory in sync with real `cd`s), which doesn't
+	// exist until interp.New returns. The closure below captures the
+	// variable, not its zero value, so this is safe: the handler is never
+	// invoked until the REPL starts feeding it lines, long after runner is
+	// assigned.
+	var runner *interp.Runner
+	r, err := interp.New(
+		interp.StdIO(os.Stdin, os.Stdout, os.Stderr),
+		interp.CallHandler(func(ctx context.Context, args []string) ([]string, error) {
+			return jobControlCallHandler(ctx, args, runner)
+		}),
+		interp.ExecHandlers(builtinExecHandler),
+	)
+	if err != nil {
+		return nil, err
+	}
+	runner = r
+	return r, nil
+}
+
+// jobControlCallHandler intercepts commands the REPL needs to special-case:
+//   - `cd`, so the process's real working directory (and thus os.Getwd()
+//     based code like customLs and star's relative install paths) stays in
+//     sync with the runner's own notion of its directory.
+//   - `jobs`, `fg`, `bg` and `kill %N`, since mvdan.cc/sh either doesn't
+//     implement them (fg/bg are "unimplemented builtin") or, for kill,
+//     would otherwise just exec the real `kill` binary with a "%N"
+//     argument it doesn't understand.
+func jobControlCallHandler(ctx context.Context, args []string, runner *interp.Runner) ([]string, error) {
+	if args[0] == "cd" {
+		dir := "."
+		if len(args) > 1 {
+			dir = args[1]
+		}
+		if dir == "~" {
+			dir = getHomeDirectory()
+		}
+		if err := changeDirectory(dir); err != nil {
+			fmt.Fprintln(os.Stderr, BoldRed+err.Error()+Reset)
+			return []string{":"}, nil
+		}
+		if wd, err := os.Getwd(); err == nil {
+			interp.Dir(wd)(runner)
+		}
+		return []string{":"}, nil
+	}
+
+	spec := ""
+	if len(args) > 1 {
+		spec = args[1]
+	}
+
+	var err error
+	switch {
+	case args[0] == "jobs":
+		err = jobsBuiltin()
+	case args[0] == "fg":
+		err = fgBuiltin(ctx, spec)
+	case args[0] == "bg":
+		err = bgBuiltin(spec)
+	case args[0] == "kill" && strings.HasPrefix(spec, "%"):
+		err = killJobBuiltin(spec)
+	default:
+		return args, nil
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, BoldRed+"[ERROR] "+err.Error()+Reset)
+	}
+	// Report ":" instead so the Runner doesn't also try to run these as
+	// real commands or builtins.
+	return []string{":"}, nil
+}
+
+// builtinExecHandler runs ls/clear/star directly and starts every other
+// external command itself through runExternal, tracking it in the jobs
+// table (so Ctrl-C/Ctrl-Z and fg/bg/jobs can act on it). It never calls
+// next, the default handler.
+func builtinExecHandler(next interp.ExecHandlerFunc) interp.ExecHandlerFunc {
+	return func(ctx context.Context, args []string) error {
+		hc := interp.HandlerCtx(ctx)
+		switch args[0] {
+		case "ls", "dir":
+			customLs(hc.Stdout)
+			return nil
+		case "clear", "cls":
+			clearScreen()
+			return nil
+		case "star":
+			runStarCommand(args[1:])
+			return nil
+		}
+
+		return runExternal(ctx, args)
+	}
+}
+
+// runExternal starts args as a real external command, in its own process
+// group so job control and Ctrl-C only ever affect it, and registers it in
+// the jobs table for the duration of the run.
+func runExternal(ctx context.Context, args []string) error {
+	hc := interp.HandlerCtx(ctx)
+
+	path, err := interp.LookPathDir(hc.Dir, hc.Env, args[0])
+	if err != nil {
+		fmt.Fprintln(hc.Stderr, err)
+		return interp.NewExitStatus(127)
+	}
+
+	cmd := &exec.Cmd{
+		Path:        path,
+		Args:        args,
+		Env:         environFrom(hc.Env),
+		Dir:         hc.Dir,
+		Stdin:       hc.Stdin,
+		Stdout:      hc.Stdout,
+		Stderr:      hc.Stderr,
+		SysProcAttr: newProcAttr(),
+	}
+
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintln(hc.Stderr, err)
+		return interp.NewExitStatus(126)
+	}
+
+	j := registerJob(cmd, strings.Join(args, " "))
+	go reapJob(j)
+
+	exited, err := waitForeground(ctx, j)
+	if !exited {
+		return nil
+	}
+	return exitStatusFromErr(err)
+}
+
+// exitStatusFromErr converts the error from cmd.Wait() into the exit-status
+// error interp.Run expects.
+func exitStatusFromErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return interp.NewExitStatus(uint8(exitErr.ExitCode()))
+	}
+	return err
+}
+
+// environFrom flattens the interpreter's view of the environment into the
+// os/exec-style "KEY=value" slice external commands expect.
+func environFrom(env expand.Environ) []string {
+	var list []string
+	env.Each(func(name string, vr expand.Variable) bool {
+		if vr.Exported && vr.Kind == expand.String {
+			list = append(list, name+"="+vr.String())
+		}
+		return true
+	})
+	return list
+}
+
+// splitRepo parses a "user/repo" argument, reporting ok=false (rather than
+// panicking on a short slice) if it's missing the slash.
+func splitRepo(repo string) (user, name string, ok bool) {
+	user, name, found := strings.Cut(repo, "/")
+	if !found || user == "" || name == "" {
+		return "", "", false
+	}
+	return user, name, true
+}
+
+// runStarCommand implements the `star` builtin's subcommands.
+func runStarCommand(args []string) {
+	if len(args) < 1 {
+		fmt.Println(BoldRed + "[ERROR] Missing subcommand. Use 'star install user/repo' or other commands." + Reset)
+		return
+	}
+
+	switch args[0] {
+	case "install":
+		if len(args) < 2 {
+			fmt.Println(BoldRed + "[ERROR] Missing repository argument. Use 'star install user/repo'." + Reset)
+			return
+		}
+		repo := args[1]
+		user, name, ok := splitRepo(repo)
+		if !ok {
+			fmt.Println(BoldRed + "[ERROR] Invalid repository " + repo + ". Use 'star install user/repo'." + Reset)
+			return
+		}
+		fmt.Println(DefaultGreen + "Installing " + repo + "..." + Reset)
+		err := star.Install(star.Package{User: user, Repo: name})
+		if err != nil {
+			fmt.Println(BoldRed + "[ERROR] Installation failed: " + err.Error() + Reset)
+		} else {
+			fmt.Println(DefaultGreen + "[SUCCESS] " + repo + " installed successfully!" + Reset)
+		}
+
+	case "list":
+		installed, err := star.ListInstalledStars()
+		if err != nil {
+			fmt.Println(BoldRed + "[ERROR] Could not list installed packages: " + err.Error() + Reset)
+			return
+		}
+		for _, pkg := range installed {
+			fmt.Printf(DefaultGreen+"- %s/%s@%s\n"+Reset, pkg.User, pkg.Repo, pkg.Version)
+		}
+
+	case "uninstall":
+		if len(args) < 2 {
+			fmt.Println(BoldRed + "[ERROR] Missing repository argument. Use 'star uninstall user/repo'." + Reset)
+			return
+		}
+		repo := args[1]
+		user, name, ok := splitRepo(repo)
+		if !ok {
+			fmt.Println(BoldRed + "[ERROR] Invalid repository " + repo + ". Use 'star uninstall user/repo'." + Reset)
+			return
+		}
+		fmt.Println(DefaultRed + "Uninstalling " + repo + "..." + Reset)
+		err := star.Uninstall(star.Package{User: user, Repo: name})
+		if err != nil {
+			fmt.Println(BoldRed + "[ERROR] Uninstallation failed: " + err.Error() + Reset)
+		} else {
+			fmt.Println(DefaultGreen + "[SUCCESS] " + repo + " uninstalled successfully!" + Reset)
+		}
+
+	case "update":
+		if len(args) >= 2 {
+			repo := args[1]
+			user, name, ok := splitRepo(repo)
+			if !ok {
+				fmt.Println(BoldRed + "[ERROR] Invalid repository " + repo + ". Use 'star update user/repo'." + Reset)
+				return
+			}
+			fmt.Println(DefaultGreen + "Updating " + repo + "..." + Reset)
+			err := star.Update(star.Package{User: user, Repo: name})
+			if err != nil {
+				fmt.Println(BoldRed + "[ERROR] Update failed: " + err.Error() + Reset)
+			} else {
+				fmt.Println(DefaultGreen + "[SUCCESS] " + repo + " updated successfully!" + Reset)
+			}
+			return
+		}
+
+		updates, err := star.CheckUpdates()
+		if err != nil {
+			fmt.Println(BoldRed + "[ERROR] Could not check for updates: " + err.Error() + Reset)
+			return
+		}
+		if len(updates) == 0 {
+			fmt.Println(DefaultGreen + "Everything is up to date." + Reset)
+			return
+		}
+		for _, u := range updates {
+			fmt.Printf(DefaultGreen+"- %s/%s: %s -> %s\n"+Reset, u.Package.User, u.Package.Repo, u.Package.Version, u.LatestVersion)
+		}
+
+	case "why":
+		if len(args) < 2 {
+			fmt.Println(BoldRed + "[ERROR] Missing repository argument. Use 'star why user/repo'." + Reset)
+			return
+		}
+		repo := args[1]
+		user, name, ok := splitRepo(repo)
+		if !ok {
+			fmt.Println(BoldRed + "[ERROR] Invalid repository " + repo + ". Use 'star why user/repo'." + Reset)
+			return
+		}
+		chain, err := star.Why(user, name)
+		if err != nil {
+			fmt.Println(BoldRed + "[ERROR] " + err.Error() + Reset)
+			return
+		}
+		names := make([]string, len(chain))
+		for i, pkg := range chain {
+			names[i] = fmt.Sprintf("%s/%s@%s", pkg.User, pkg.Repo, pkg.Version)
+		}
+		fmt.Println(DefaultGreen + strings.Join(names, " <- required by ") + Reset)
+
+	default:
+		fmt.Println(BoldRed + "[ERROR] Unknown 'star' subcommand." + Reset)
+	}
+}
+
+// expandAlias rewrites input's leading word if it matches a configured
+// alias, the same substitution the old hand-rolled executor did, just done
+// before the line reaches the parser so it composes with pipes and
+// redirects.
+func expandAlias(input string) string {
+	fields := strings.Fields(input)
+	if len(fields) == 0 {
+		return input
+	}
+	alias, ok := config.Aliases[fields[0]]
+	if !ok {
+		return input
+	}
+	return alias + " " + strings.Join(fields[1:], " ")
+}
+
+// runLine parses and executes a single line of shell input.
+func runLine(runner *interp.Runner, input string) {
+	input = expandAlias(input)
+
+	file, err := syntax.NewParser().Parse(strings.NewReader(input), "")
+	if err != nil {
+		fmt.Println(BoldRed + "[ERROR] " + err.Error() + Reset)
+		return
+	}
+
+	if err := runner.Run(context.Background(), file); isScriptError(err) {
+		fmt.Println(BoldRed + "[ERROR] " + err.Error() + Reset)
+	}
+}
+
+// isScriptError reports whether err is a real failure worth printing,
+// as opposed to just the command's exit status (e.g. a non-zero exit from
+// `false` isn't something the REPL needs to complain about).
+func isScriptError(err error) bool {
+	if err == nil {
+		return false
+	}
+	_, isExitStatus := interp.IsExitStatus(err)
+	return !isExitStatus
+}
+
+// loadRCFile runs ~/.starshellrc at startup, if it exists, so users can
+// define functions, aliases and environment variables for the session.
+func loadRCFile(runner *interp.Runner) error {
+	path := filepath.Join(getHomeDirectory(), ".starshellrc")
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	file, err := syntax.NewParser().Parse(strings.NewReader(string(data)), path)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if err := runner.Run(context.Background(), file); isScriptError(err) {
+		return fmt.Errorf("failed to run %s: %w", path, err)
+	}
+	return nil
+}
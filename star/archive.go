@@ -0,0 +1,174 @@
+package star
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// binDir is where binaries extracted from archives are installed.
+const binDir = "./stars/bin"
+
+// isArchive reports whether name looks like an archive format Install
+// knows how to extract.
+func isArchive(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz") || strings.HasSuffix(lower, ".zip")
+}
+
+// extractBinary extracts the single binary matching entrypoint (a glob, or
+// the repo name if entrypoint is empty) from the archive at archivePath and
+// installs it into binDir. It returns the path of the extracted file,
+// relative to installDir, so it can be recorded in the package database.
+func extractBinary(archivePath, repo, entrypoint string) (string, error) {
+	if err := os.MkdirAll(binDir, os.ModePerm); err != nil {
+		return "", fmt.Errorf("failed to create bin directory: %w", err)
+	}
+
+	pattern := entrypoint
+	if pattern == "" {
+		pattern = repo
+	}
+
+	lower := strings.ToLower(archivePath)
+	var entries []archiveEntry
+	var err error
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		entries, err = readZip(archivePath)
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		entries, err = readTarGz(archivePath)
+	default:
+		return "", fmt.Errorf("unsupported archive format: %s", archivePath)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	entry, err := matchArchiveEntry(entries, pattern)
+	if err != nil {
+		return "", err
+	}
+
+	destName := filepath.Base(entry.name)
+	destPath := filepath.Join(binDir, destName)
+	if err := os.WriteFile(destPath, entry.data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write extracted binary: %w", err)
+	}
+
+	if runtime.GOOS != "windows" {
+		if err := os.Chmod(destPath, 0755); err != nil {
+			return "", fmt.Errorf("failed to chmod extracted binary: %w", err)
+		}
+	}
+
+	relPath, err := filepath.Rel(installDir, destPath)
+	if err != nil {
+		return "", err
+	}
+	return relPath, nil
+}
+
+// archiveEntry is a single regular file read out of an archive.
+type archiveEntry struct {
+	name string
+	data []byte
+}
+
+// matchArchiveEntry finds the entry whose base name matches pattern, either
+// exactly, as a glob, or (failing both) case-insensitively, each with or
+// without a ".exe" suffix for the exact forms. Prefixes are not matched.
+// Directories and non-matching entries are skipped.
+func matchArchiveEntry(entries []archiveEntry, pattern string) (archiveEntry, error) {
+	var tried []string
+	for _, e := range entries {
+		base := filepath.Base(e.name)
+		tried = append(tried, base)
+
+		if matched, _ := filepath.Match(pattern, base); matched {
+			return e, nil
+		}
+		if base == pattern || base == pattern+".exe" {
+			return e, nil
+		}
+	}
+
+	for _, e := range entries {
+		base := filepath.Base(e.name)
+		if strings.EqualFold(base, pattern) || strings.EqualFold(base, pattern+".exe") {
+			return e, nil
+		}
+	}
+
+	return archiveEntry{}, fmt.Errorf("no entry matching %q found in archive (entries: %s)", pattern, strings.Join(tried, ", "))
+}
+
+// readZip reads every regular file entry out of a zip archive.
+func readZip(path string) ([]archiveEntry, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip archive: %w", err)
+	}
+	defer r.Close()
+
+	var entries []archiveEntry
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read zip entry %s: %w", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read zip entry %s: %w", f.Name, err)
+		}
+		entries = append(entries, archiveEntry{name: f.Name, data: data})
+	}
+	return entries, nil
+}
+
+// readTarGz reads every regular file entry out of a gzip-compressed tar
+// archive.
+func readTarGz(path string) ([]archiveEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open gzip stream: %w", err)
+	}
+	defer gz.Close()
+
+	var entries []archiveEntry
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar entry: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar entry %s: %w", hdr.Name, err)
+		}
+		entries = append(entries, archiveEntry{name: hdr.Name, data: data})
+	}
+	return entries, nil
+}
@@ -0,0 +1,130 @@
+package star
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// checksumSuffixes are the companion asset names (or suffixes) release
+// workflows commonly ship alongside a binary/archive to let installers
+// verify its integrity.
+var checksumSuffixes = []string{".sha256", ".sha256sum"}
+var checksumFilenames = []string{"SHA256SUMS", "checksums.txt"}
+
+// findChecksumAsset returns the release asset that carries the SHA-256 sum
+// for file, if any such companion asset was published alongside it.
+func findChecksumAsset(assets []Asset, file string) (Asset, bool) {
+	lowerFile := strings.ToLower(file)
+
+	for _, suffix := range checksumSuffixes {
+		for _, asset := range assets {
+			if strings.ToLower(asset.Name) == lowerFile+suffix {
+				return asset, true
+			}
+		}
+	}
+
+	for _, name := range checksumFilenames {
+		for _, asset := range assets {
+			if strings.EqualFold(asset.Name, name) {
+				return asset, true
+			}
+		}
+	}
+
+	return Asset{}, false
+}
+
+// verifyChecksum downloads the checksum asset for file and compares it
+// against the SHA-256 of the file already on disk at destPath. It returns
+// an error if the sums don't match, or if the checksum asset doesn't
+// actually contain an entry for file.
+func verifyChecksum(asset Asset, file, destPath string) error {
+	resp, err := http.Get(asset.URL)
+	if err != nil {
+		return fmt.Errorf("failed to fetch checksum file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch checksum file: status code %d", resp.StatusCode)
+	}
+
+	expected, err := parseChecksumEntry(resp.Body, file)
+	if err != nil {
+		return err
+	}
+
+	actual, err := sha256File(destPath)
+	if err != nil {
+		return fmt.Errorf("failed to hash downloaded file: %w", err)
+	}
+
+	if !strings.EqualFold(expected, actual) {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", file, expected, actual)
+	}
+
+	return nil
+}
+
+// parseChecksumEntry scans a standard `sha256sum -b` formatted file
+// (`<hex>  <filename>` per line) and returns the hex digest recorded for
+// file. If the file has only a single entry (the common case for
+// `<file>.sha256` companions) and it doesn't name file explicitly, that
+// lone entry is used.
+func parseChecksumEntry(r io.Reader, file string) (string, error) {
+	base := filepath.Base(file)
+
+	var lines []struct{ sum, name string }
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 1 {
+			return fields[0], nil
+		}
+		name := strings.TrimPrefix(fields[len(fields)-1], "*")
+		lines = append(lines, struct{ sum, name string }{fields[0], name})
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read checksum file: %w", err)
+	}
+
+	for _, l := range lines {
+		if l.name == base || filepath.Base(l.name) == base {
+			return l.sum, nil
+		}
+	}
+
+	if len(lines) == 1 {
+		return lines[0].sum, nil
+	}
+
+	return "", fmt.Errorf("no checksum entry found for %s", base)
+}
+
+// sha256File computes the SHA-256 digest of the file at path, hex-encoded.
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,128 @@
+package star
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// dbDir holds one JSON record per installed package, keyed by "user_repo".
+const dbDir = "./stars/db"
+
+const lockRetryInterval = 50 * time.Millisecond
+const lockTimeout = 10 * time.Second
+
+// DB is a directory-backed package database: one JSON file per installed
+// package, plus a lock file serializing writers.
+type DB struct {
+	Dir string
+}
+
+// defaultDB is the DB used by Install, Uninstall, Update and friends.
+var defaultDB = &DB{Dir: dbDir}
+
+// recordPath returns the path of the JSON record for user/repo.
+func (db *DB) recordPath(user, repo string) string {
+	name := strings.ToLower(user) + "_" + strings.ToLower(repo) + ".json"
+	return filepath.Join(db.Dir, name)
+}
+
+// Load reads the record for user/repo. The second return value is false if
+// no such package is installed.
+func (db *DB) Load(user, repo string) (Package, bool, error) {
+	data, err := os.ReadFile(db.recordPath(user, repo))
+	if errors.Is(err, os.ErrNotExist) {
+		return Package{}, false, nil
+	}
+	if err != nil {
+		return Package{}, false, fmt.Errorf("failed to read package record: %w", err)
+	}
+
+	var pkg Package
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return Package{}, false, fmt.Errorf("failed to parse package record: %w", err)
+	}
+	return pkg, true, nil
+}
+
+// Save writes (or overwrites) the record for pkg.
+func (db *DB) Save(pkg Package) error {
+	if err := os.MkdirAll(db.Dir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create package database: %w", err)
+	}
+
+	data, err := json.MarshalIndent(pkg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode package record: %w", err)
+	}
+
+	return os.WriteFile(db.recordPath(pkg.User, pkg.Repo), data, 0644)
+}
+
+// Delete removes the record for user/repo. It's a no-op if no such record
+// exists.
+func (db *DB) Delete(user, repo string) error {
+	err := os.Remove(db.recordPath(user, repo))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove package record: %w", err)
+	}
+	return nil
+}
+
+// List returns every installed package recorded in the database.
+func (db *DB) List() ([]Package, error) {
+	entries, err := os.ReadDir(db.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package database: %w", err)
+	}
+
+	var packages []Package
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(db.Dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read package record %s: %w", entry.Name(), err)
+		}
+		var pkg Package
+		if err := json.Unmarshal(data, &pkg); err != nil {
+			return nil, fmt.Errorf("failed to parse package record %s: %w", entry.Name(), err)
+		}
+		packages = append(packages, pkg)
+	}
+	return packages, nil
+}
+
+// Lock acquires the package database's lock file, blocking (with a timeout)
+// until it's free. The returned func releases it; callers must always call
+// it, typically via defer.
+func (db *DB) Lock() (func(), error) {
+	if err := os.MkdirAll(db.Dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("failed to create package database: %w", err)
+	}
+
+	path := filepath.Join(db.Dir, ".lock")
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to acquire package database lock: %w", err)
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for package database lock at %s", path)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
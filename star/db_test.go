@@ -0,0 +1,97 @@
+package star
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDBSaveLoadDelete(t *testing.T) {
+	db := &DB{Dir: t.TempDir()}
+
+	if _, ok, err := db.Load("a", "b"); err != nil || ok {
+		t.Fatalf("Load() on empty db = (_, %v, %v), want (_, false, nil)", ok, err)
+	}
+
+	pkg := Package{User: "a", Repo: "b", Version: "v1.0.0", File: "b"}
+	if err := db.Save(pkg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, ok, err := db.Load("A", "B")
+	if err != nil || !ok {
+		t.Fatalf("Load() = (_, %v, %v), want (_, true, nil)", ok, err)
+	}
+	if !reflect.DeepEqual(got, pkg) {
+		t.Fatalf("Load() = %+v, want %+v", got, pkg)
+	}
+
+	if err := db.Delete("a", "b"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, ok, err := db.Load("a", "b"); err != nil || ok {
+		t.Fatalf("Load() after Delete = (_, %v, %v), want (_, false, nil)", ok, err)
+	}
+
+	// Deleting an already-absent record is a no-op, not an error.
+	if err := db.Delete("a", "b"); err != nil {
+		t.Fatalf("Delete() of absent record error = %v, want nil", err)
+	}
+}
+
+func TestDBList(t *testing.T) {
+	db := &DB{Dir: t.TempDir()}
+
+	if packages, err := db.List(); err != nil || len(packages) != 0 {
+		t.Fatalf("List() on empty db = (%v, %v), want (nil, nil)", packages, err)
+	}
+
+	want := []Package{
+		{User: "a", Repo: "one", Version: "v1.0.0"},
+		{User: "a", Repo: "two", Version: "v2.0.0"},
+	}
+	for _, pkg := range want {
+		if err := db.Save(pkg); err != nil {
+			t.Fatalf("Save(%+v) error = %v", pkg, err)
+		}
+	}
+
+	got, err := db.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("List() returned %d packages, want %d", len(got), len(want))
+	}
+}
+
+func TestDBLockExcludesConcurrentLockers(t *testing.T) {
+	db := &DB{Dir: t.TempDir()}
+
+	unlock, err := db.Lock()
+	if err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		second, err := db.Lock()
+		if err != nil {
+			t.Errorf("second Lock() error = %v", err)
+			return
+		}
+		second()
+		close(acquired)
+	}()
+
+	// The second Lock() must retry until the first is released rather than
+	// acquiring it immediately, so it should still be waiting here.
+	select {
+	case <-acquired:
+		t.Fatal("second Lock() acquired the lock before the first was released")
+	case <-time.After(2 * lockRetryInterval):
+	}
+
+	unlock()
+	<-acquired
+}
@@ -0,0 +1,208 @@
+package star
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/schollz/progressbar/v3"
+	"golang.org/x/term"
+)
+
+// maxDownloadAttempts is how many times each URL (the original and each
+// mirror) is attempted before moving on to the next one.
+const maxDownloadAttempts = 3
+
+// Downloader fetches a URL to a local path. It's an interface so tests can
+// swap in a fake rather than hitting the network.
+type Downloader interface {
+	// Download fetches url to destPath. If verify is non-nil, it's called
+	// with the path to the downloaded file before that file is put at
+	// destPath, and the download is considered failed (and destPath is left
+	// untouched) if verify returns an error.
+	Download(url, destPath string, verify func(path string) error) error
+}
+
+// HTTPDownloader is the default Downloader, used by Install. It reports
+// progress to stdout, resumes partial downloads via HTTP range requests,
+// and retries across mirrors with exponential backoff.
+type HTTPDownloader struct {
+	// Mirrors are tried, in order, after url itself fails. A mirror is a URL
+	// prefix prepended in front of the original URL (e.g. "ghproxy.com/" ->
+	// "ghproxy.com/https://github.com/..."), the convention used by
+	// ghproxy.com-style rewriting proxies.
+	Mirrors []string
+}
+
+// newDownloader builds the Downloader used by downloadExecutable. It's a
+// package var, rather than a call to HTTPDownloader directly, so tests can
+// substitute a mock Downloader without touching the network.
+var newDownloader = func(mirrors []string) Downloader {
+	return &HTTPDownloader{Mirrors: mirrors}
+}
+
+// githubMirrorsFromEnv reads STARSHELL_GITHUB_MIRROR for a comma-separated
+// list of mirror URL prefixes (e.g. "https://ghproxy.com/").
+func githubMirrorsFromEnv() []string {
+	raw := os.Getenv("STARSHELL_GITHUB_MIRROR")
+	if raw == "" {
+		return nil
+	}
+
+	var mirrors []string
+	for _, m := range strings.Split(raw, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			mirrors = append(mirrors, m)
+		}
+	}
+	return mirrors
+}
+
+// Download fetches url to destPath, resuming from destPath+".part" if one
+// already exists, and falling back to configured mirrors on failure.
+func (d *HTTPDownloader) Download(url, destPath string, verify func(path string) error) error {
+	urls := append([]string{url}, d.mirrorURLs(url)...)
+
+	var lastErr error
+	for _, u := range urls {
+		for attempt := 0; attempt < maxDownloadAttempts; attempt++ {
+			if attempt > 0 {
+				time.Sleep(backoff(attempt))
+			}
+			if err := downloadOnce(u, destPath, verify); err != nil {
+				lastErr = err
+				continue
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("failed to download after trying %d source(s): %w", len(urls), lastErr)
+}
+
+// mirrorURLs rewrites url against each configured mirror prefix.
+func (d *HTTPDownloader) mirrorURLs(url string) []string {
+	var mirrored []string
+	for _, mirror := range d.Mirrors {
+		mirrored = append(mirrored, strings.TrimRight(mirror, "/")+"/"+url)
+	}
+	return mirrored
+}
+
+// backoff returns an exponential backoff delay for the given (1-indexed)
+// attempt number.
+func backoff(attempt int) time.Duration {
+	return time.Duration(1<<(attempt-1)) * 500 * time.Millisecond
+}
+
+// downloadOnce performs a single download attempt, resuming destPath+".part"
+// if present. The part file is only renamed to destPath once verify (if
+// given) has accepted it, so destPath never holds a downloaded-but-unverified
+// file; on a verify failure the part file is removed rather than left
+// behind, since retrying against it (via Range) would just re-validate the
+// same bad bytes instead of re-fetching them.
+func downloadOnce(url, destPath string, verify func(path string) error) error {
+	partPath := destPath + ".part"
+
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		offset = 0
+		flags |= os.O_TRUNC
+	default:
+		return fmt.Errorf("failed to download file: status code %d", resp.StatusCode)
+	}
+
+	file, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open partial file: %w", err)
+	}
+	defer file.Close()
+
+	total := resp.ContentLength
+	if total > 0 {
+		total += offset
+	}
+
+	bar := newProgressBar(total, destPath)
+	if _, err := io.Copy(io.MultiWriter(file, bar), resp.Body); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to finalize file: %w", err)
+	}
+
+	if verify != nil {
+		if err := verify(partPath); err != nil {
+			os.Remove(partPath)
+			return fmt.Errorf("checksum verification failed: %w", err)
+		}
+	}
+
+	return os.Rename(partPath, destPath)
+}
+
+// newProgressBar builds a progress reporter for a download of the given
+// total size (may be -1 if unknown). When stdout isn't a TTY, progressbar's
+// `\r`-redrawn bar degrades to a stream of disjoint fragments rather than
+// readable output, so that case gets its own plain percentage line instead.
+func newProgressBar(total int64, label string) io.Writer {
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		return &plainProgressWriter{label: label, total: total}
+	}
+
+	return progressbar.NewOptions64(total,
+		progressbar.OptionSetDescription(label),
+		progressbar.OptionShowBytes(true),
+		progressbar.OptionShowCount(),
+		progressbar.OptionThrottle(100*time.Millisecond),
+	)
+}
+
+// plainProgressWriter reports download progress as one "label: N%" line per
+// percentage point, with no carriage-return redraw, so piped/non-interactive
+// output (logs, CI) gets clean, appendable lines instead of a redrawn bar.
+type plainProgressWriter struct {
+	label   string
+	total   int64
+	written int64
+	lastPct int
+}
+
+func (w *plainProgressWriter) Write(p []byte) (int, error) {
+	w.written += int64(len(p))
+	if w.total <= 0 {
+		return len(p), nil
+	}
+
+	if pct := int(w.written * 100 / w.total); pct > w.lastPct {
+		w.lastPct = pct
+		fmt.Printf("%s: %d%%\n", w.label, pct)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,143 @@
+package star
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDownloadOnceFetchesFile(t *testing.T) {
+	const content = "hello"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, content)
+	}))
+	defer srv.Close()
+
+	destPath := filepath.Join(t.TempDir(), "asset")
+	if err := downloadOnce(srv.URL, destPath, nil); err != nil {
+		t.Fatalf("downloadOnce() error = %v", err)
+	}
+
+	got, err := os.ReadFile(destPath)
+	if err != nil {
+		t.Fatalf("ReadFile(destPath) error = %v", err)
+	}
+	if string(got) != content {
+		t.Fatalf("destPath content = %q, want %q", got, content)
+	}
+	if _, err := os.Stat(destPath + ".part"); !os.IsNotExist(err) {
+		t.Fatalf(".part file still exists after a successful download")
+	}
+}
+
+func TestDownloadOnceResumesFromPartialFile(t *testing.T) {
+	const content = "0123456789"
+	var gotRange string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRange = r.Header.Get("Range")
+		if gotRange == "" {
+			io.WriteString(w, content)
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes 5-9/%d", len(content)))
+		w.WriteHeader(http.StatusPartialContent)
+		io.WriteString(w, content[5:])
+	}))
+	defer srv.Close()
+
+	destPath := filepath.Join(t.TempDir(), "asset")
+	if err := os.WriteFile(destPath+".part", []byte(content[:5]), 0644); err != nil {
+		t.Fatalf("seeding .part file: %v", err)
+	}
+
+	if err := downloadOnce(srv.URL, destPath, nil); err != nil {
+		t.Fatalf("downloadOnce() error = %v", err)
+	}
+
+	if gotRange != "bytes=5-" {
+		t.Fatalf("Range header = %q, want %q", gotRange, "bytes=5-")
+	}
+	got, err := os.ReadFile(destPath)
+	if err != nil {
+		t.Fatalf("ReadFile(destPath) error = %v", err)
+	}
+	if string(got) != content {
+		t.Fatalf("destPath content = %q, want %q (resumed bytes should be appended, not re-fetched)", got, content)
+	}
+}
+
+func TestHTTPDownloaderFallsBackToMirror(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "from-mirror")
+	}))
+	defer mirror.Close()
+
+	d := &HTTPDownloader{Mirrors: []string{mirror.URL}}
+	destPath := filepath.Join(t.TempDir(), "asset")
+	if err := d.Download(primary.URL, destPath, nil); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+
+	got, err := os.ReadFile(destPath)
+	if err != nil {
+		t.Fatalf("ReadFile(destPath) error = %v", err)
+	}
+	if string(got) != "from-mirror" {
+		t.Fatalf("destPath content = %q, want content served by the mirror", got)
+	}
+}
+
+func TestDownloadOnceVerifyFailureLeavesDestUnwrittenAndRemovesPart(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "corrupt")
+	}))
+	defer srv.Close()
+
+	destPath := filepath.Join(t.TempDir(), "asset")
+	verifyErr := fmt.Errorf("checksum mismatch")
+	err := downloadOnce(srv.URL, destPath, func(path string) error { return verifyErr })
+	if err == nil {
+		t.Fatal("downloadOnce() error = nil, want a verification error")
+	}
+
+	if _, err := os.Stat(destPath); !os.IsNotExist(err) {
+		t.Fatal("destPath exists after a failed verification, want it left untouched")
+	}
+	if _, err := os.Stat(destPath + ".part"); !os.IsNotExist(err) {
+		t.Fatal(".part file still exists after a failed verification, want it removed so a retry re-fetches instead of re-validating the same bytes")
+	}
+}
+
+func TestDownloadOnceVerifySuccessRenamesToDestPath(t *testing.T) {
+	const content = "good"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, content)
+	}))
+	defer srv.Close()
+
+	destPath := filepath.Join(t.TempDir(), "asset")
+	var verifiedPath string
+	err := downloadOnce(srv.URL, destPath, func(path string) error {
+		verifiedPath = path
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("downloadOnce() error = %v", err)
+	}
+
+	if verifiedPath != destPath+".part" {
+		t.Fatalf("verify was called with %q, want the .part path (verification must happen before the rename)", verifiedPath)
+	}
+	if got, err := os.ReadFile(destPath); err != nil || string(got) != content {
+		t.Fatalf("destPath content = (%q, %v), want (%q, nil)", got, err, content)
+	}
+}
@@ -0,0 +1,187 @@
+package star
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// githubCacheDir holds cached GitHub API responses, keyed by request URL,
+// so repeated lookups (e.g. CheckUpdates scanning every installed package)
+// don't burn the unauthenticated rate limit on data that hasn't changed.
+func githubCacheDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return filepath.Join(base, "starshell", "github"), nil
+}
+
+// githubCacheEntry is what's persisted alongside a cached response body so
+// the next request can be conditional.
+type githubCacheEntry struct {
+	ETag         string          `json:"etag,omitempty"`
+	LastModified string          `json:"last_modified,omitempty"`
+	Body         json.RawMessage `json:"body"`
+}
+
+// githubClient fetches from api.github.com with authentication, ETag-based
+// caching, and rate-limit awareness. It's the only thing in the star
+// package that's allowed to call the GitHub API directly.
+type githubClient struct {
+	token   string
+	cache   string
+	verbose bool
+}
+
+// defaultGithubClient is the client used by fetchRelease. It's a package
+// var, rather than a call to newGithubClient directly, so it can be swapped
+// out in tests without touching the network.
+var defaultGithubClient = newGithubClient()
+
+// newGithubClient builds a githubClient from the environment and
+// config.json: GITHUB_TOKEN / STARSHELL_GITHUB_TOKEN take precedence over
+// config.json's "github_token", and STARSHELL_VERBOSE enables logging
+// remaining quota to stderr.
+func newGithubClient() *githubClient {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("STARSHELL_GITHUB_TOKEN")
+	}
+	if token == "" {
+		if cfg, ok := loadStarConfig(); ok {
+			token = cfg.GithubToken
+		}
+	}
+
+	cacheDir, err := githubCacheDir()
+	if err != nil {
+		cacheDir = ""
+	}
+
+	return &githubClient{
+		token:   token,
+		cache:   cacheDir,
+		verbose: os.Getenv("STARSHELL_VERBOSE") != "",
+	}
+}
+
+// cachePath returns the path used to cache responses for url.
+func (c *githubClient) cachePath(url string) string {
+	if c.cache == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.cache, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get fetches url, replaying a cached response on a 304 and respecting the
+// GitHub API's rate limit. The returned bytes are the response body, taken
+// from the cache if the server reports nothing has changed.
+func (c *githubClient) Get(url string) ([]byte, error) {
+	path := c.cachePath(url)
+
+	var cached *githubCacheEntry
+	if path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			var entry githubCacheEntry
+			if json.Unmarshal(data, &entry) == nil {
+				cached = &entry
+			}
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach GitHub API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	c.logQuota(resp)
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return cached.Body, nil
+	}
+
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+		if remaining := resp.Header.Get("X-RateLimit-Remaining"); remaining == "0" {
+			return nil, fmt.Errorf("GitHub API rate limit exceeded, resets at %s (set GITHUB_TOKEN to raise the limit)", resetTime(resp))
+		}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GitHub API response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API request failed: status code %d", resp.StatusCode)
+	}
+
+	if path != "" {
+		entry := githubCacheEntry{
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			Body:         body,
+		}
+		if data, err := json.Marshal(entry); err == nil {
+			if err := os.MkdirAll(c.cache, os.ModePerm); err == nil {
+				os.WriteFile(path, data, 0644)
+			}
+		}
+	}
+
+	return body, nil
+}
+
+// logQuota prints the remaining rate-limit quota to stderr when verbose
+// logging is enabled.
+func (c *githubClient) logQuota(resp *http.Response) {
+	if !c.verbose {
+		return
+	}
+	remaining := resp.Header.Get("X-RateLimit-Remaining")
+	limit := resp.Header.Get("X-RateLimit-Limit")
+	if remaining == "" || limit == "" {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "github: %s/%s requests remaining this window\n", remaining, limit)
+}
+
+// resetTime formats the X-RateLimit-Reset header (a Unix timestamp) as a
+// human-readable local time. It returns "unknown" if the header is absent
+// and the raw header if it's unparseable.
+func resetTime(resp *http.Response) string {
+	raw := resp.Header.Get("X-RateLimit-Reset")
+	if raw == "" {
+		return "unknown"
+	}
+	secs, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return raw
+	}
+	return time.Unix(secs, 0).Local().Format(time.Kitchen)
+}
@@ -0,0 +1,129 @@
+package star
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Manifest is the `star.yaml`/`star.json` a repo can ship as a release
+// asset to declare its own dependencies.
+type Manifest struct {
+	Requires []string `json:"requires" yaml:"requires"`
+}
+
+// manifestAssetNames are the filenames Install looks for among a release's
+// assets to find a dependency manifest.
+var manifestAssetNames = []string{"star.json", "star.yaml", "star.yml"}
+
+// findManifestAsset returns the release asset carrying a dependency
+// manifest, if the release shipped one.
+func findManifestAsset(assets []Asset) (Asset, bool) {
+	for _, name := range manifestAssetNames {
+		for _, asset := range assets {
+			if strings.EqualFold(asset.Name, name) {
+				return asset, true
+			}
+		}
+	}
+	return Asset{}, false
+}
+
+// fetchManifest downloads and parses the manifest asset, if any. It's a
+// package var, like fetchRelease, so tests can substitute a fake without
+// hitting the network.
+var fetchManifest = func(assets []Asset) (*Manifest, error) {
+	asset, ok := findManifestAsset(assets)
+	if !ok {
+		return &Manifest{}, nil
+	}
+
+	resp, err := http.Get(asset.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch manifest: status code %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if strings.HasSuffix(strings.ToLower(asset.Name), ".json") {
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest: %w", err)
+		}
+		return &manifest, nil
+	}
+
+	// star.yaml/star.yml: only the "requires" list is understood, so it's
+	// parsed with a tiny line-based reader rather than pulling in a full
+	// YAML dependency for one field.
+	requires, err := parseRequiresYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	manifest.Requires = requires
+	return &manifest, nil
+}
+
+// parseRequiresYAML reads the `requires:` list out of a minimal YAML
+// manifest, e.g.:
+//
+//	requires:
+//	  - user/repo >= v1.2.0
+//	  - other/repo
+func parseRequiresYAML(data []byte) ([]string, error) {
+	var requires []string
+	inRequires := false
+	for _, rawLine := range strings.Split(string(data), "\n") {
+		line := strings.TrimRight(rawLine, "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if !inRequires {
+			if trimmed == "requires:" {
+				inRequires = true
+			}
+			continue
+		}
+
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			break
+		}
+		item := strings.TrimPrefix(trimmed, "- ")
+		item = strings.Trim(item, "\"'")
+		requires = append(requires, item)
+	}
+	return requires, nil
+}
+
+// parseRequirement splits a manifest requirement like "user/repo >= v1.2.0"
+// into the repo it names and the version constraint it imposes ("" if
+// unconstrained).
+func parseRequirement(req string) (repo, constraint string, err error) {
+	req = strings.TrimSpace(req)
+	fields := strings.Fields(req)
+	if len(fields) == 0 {
+		return "", "", fmt.Errorf("empty requirement")
+	}
+
+	repo = fields[0]
+	if !strings.Contains(repo, "/") {
+		return "", "", fmt.Errorf("invalid requirement %q: expected \"user/repo\"", req)
+	}
+
+	if len(fields) > 1 {
+		constraint = strings.Join(fields[1:], " ")
+	}
+	return repo, constraint, nil
+}
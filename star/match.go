@@ -0,0 +1,202 @@
+package star
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// Criteria describes the platform an asset must run on. OS and Arch are
+// required: an empty value, or one missing from the alias tables, matches
+// no asset at all.
+type Criteria struct {
+	OS   string
+	Arch string
+	// Libc is "musl" or "gnu", or "" if the distro doesn't care. It's only
+	// used as a tie-breaking preference, never to exclude an asset.
+	Libc string
+}
+
+// currentCriteria builds the Criteria for the platform starshell is running
+// on, detecting musl vs glibc from /etc/os-release where possible.
+func currentCriteria() Criteria {
+	return Criteria{OS: runtime.GOOS, Arch: runtime.GOARCH, Libc: detectLibc()}
+}
+
+// detectLibc returns "musl" on distros known to use it (Alpine), "gnu"
+// otherwise. Only consulted on Linux; ignored elsewhere.
+func detectLibc() string {
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+	data, err := os.ReadFile("/etc/os-release")
+	if err != nil {
+		return "gnu"
+	}
+	if strings.Contains(strings.ToLower(string(data)), "alpine") {
+		return "musl"
+	}
+	return "gnu"
+}
+
+// osAliases groups the names release workflows use for each GOOS value
+// starshell supports.
+var osAliases = map[string][]string{
+	"darwin":  {"darwin", "macos", "osx"},
+	"linux":   {"linux"},
+	"windows": {"windows", "win"},
+}
+
+// archAliases groups the names release workflows use for each GOARCH value
+// starshell supports. Bare "x86" is deliberately left off of 386: in
+// practice it's ambiguous with "x86_64" (64-bit) far more often than it
+// means 32-bit, and 386 releases almost always spell themselves "386" or
+// "i386" instead.
+var archAliases = map[string][]string{
+	"amd64": {"amd64", "x86_64", "x64"},
+	"arm64": {"arm64", "aarch64"},
+	"386":   {"386", "i386"},
+	"arm":   {"arm", "armv7", "armhf"},
+}
+
+// excludedSuffixes and excludedNames mark companion assets (checksums,
+// signatures), which should never be picked as the main download. Archive
+// formats extractBinary can't open are not excluded, only ranked lower.
+var excludedSuffixes = []string{".sha256", ".sha256sum", ".asc", ".sig", ".minisig"}
+var excludedNames = []string{"sha256sums", "checksums.txt"}
+
+// extractableSuffixes are archive formats extractBinary can open; assets in
+// these formats are preferred over opaque installers like .deb/.rpm/.AppImage.
+var extractableSuffixes = []string{".tar.gz", ".tgz", ".zip"}
+
+// ErrNoCompatibleAsset is returned by MatchAsset when no asset in the
+// release matched the given Criteria. Tried lists every asset name that
+// was considered, so the caller can show the user what was available.
+type ErrNoCompatibleAsset struct {
+	Criteria Criteria
+	Tried    []string
+}
+
+func (e *ErrNoCompatibleAsset) Error() string {
+	return fmt.Sprintf("no asset compatible with %s/%s found (tried: %s)", e.Criteria.OS, e.Criteria.Arch, strings.Join(e.Tried, ", "))
+}
+
+// tokenize splits an asset name on common separators for token-wise
+// matching against the alias tables, e.g. "tool-v1.2.0-linux-amd64.tar.gz"
+// -> ["tool", "v1", "2", "0", "linux", "amd64", "tar", "gz"].
+func tokenize(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return r == '-' || r == '_' || r == '.'
+	})
+}
+
+// isExcluded reports whether name is a companion asset (checksum,
+// signature) rather than something installable.
+func isExcluded(name string) bool {
+	lower := strings.ToLower(name)
+	for _, suffix := range excludedSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return true
+		}
+	}
+	for _, excluded := range excludedNames {
+		if lower == excluded {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAlias reports whether name matches any of aliases. Single-word
+// aliases (the common case) must match a whole token, so "win" doesn't
+// false-positive inside "darwin". Compound aliases that embed a separator
+// themselves (e.g. "x86_64") are instead matched as a literal substring of
+// the untokenized, lowercased name, since tokenizing on "-._" would
+// otherwise split them apart and the pieces ("x86", "64") are too generic
+// to match safely on their own.
+func hasAlias(tokens []string, lowerName string, aliases []string) bool {
+	for _, a := range aliases {
+		if strings.ContainsAny(a, "-_.") {
+			if strings.Contains(lowerName, a) {
+				return true
+			}
+			continue
+		}
+		for _, t := range tokens {
+			if t == a {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scoreAsset rates how well asset matches criteria. A zero score means the
+// asset is not a candidate at all (missing OS or arch token); higher
+// scores are preferred libc, archive format, and so on.
+func scoreAsset(name string, criteria Criteria) int {
+	lower := strings.ToLower(name)
+	tokens := tokenize(name)
+
+	osAliasesFor := osAliases[criteria.OS]
+	archAliasesFor := archAliases[criteria.Arch]
+	if len(osAliasesFor) == 0 || !hasAlias(tokens, lower, osAliasesFor) {
+		return 0
+	}
+	if len(archAliasesFor) == 0 || !hasAlias(tokens, lower, archAliasesFor) {
+		return 0
+	}
+
+	score := 2
+
+	isMusl := hasAlias(tokens, lower, []string{"musl"})
+	isGnu := hasAlias(tokens, lower, []string{"gnu"})
+	switch {
+	case criteria.Libc == "musl" && isMusl:
+		score++
+	case criteria.Libc != "musl" && isMusl:
+		score--
+	case criteria.Libc != "musl" && (isGnu || (!isMusl && !isGnu)):
+		score++
+	}
+
+	for _, suffix := range extractableSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			score++
+			break
+		}
+	}
+
+	return score
+}
+
+// MatchAsset picks the release asset that best fits criteria out of
+// assets, scoring every candidate (required OS/arch tokens, libc
+// preference, preferring archive formats the installer can extract) and
+// returning the highest scorer. Companion assets (checksums, signatures)
+// are never considered. If nothing scores, it returns ErrNoCompatibleAsset
+// listing every asset name that was tried.
+func MatchAsset(assets []Asset, criteria Criteria) (Asset, error) {
+	var tried []string
+	var best Asset
+	bestScore := 0
+
+	for _, asset := range assets {
+		if isExcluded(asset.Name) {
+			continue
+		}
+		tried = append(tried, asset.Name)
+
+		score := scoreAsset(asset.Name, criteria)
+		if score > bestScore {
+			bestScore = score
+			best = asset
+		}
+	}
+
+	if bestScore == 0 {
+		return Asset{}, &ErrNoCompatibleAsset{Criteria: criteria, Tried: tried}
+	}
+	return best, nil
+}
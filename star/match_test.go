@@ -0,0 +1,320 @@
+package star
+
+import (
+	"errors"
+	"testing"
+)
+
+// assetsNamed builds a release asset list from bare names, for tests that
+// don't care about browser_download_url.
+func assetsNamed(names ...string) []Asset {
+	assets := make([]Asset, len(names))
+	for i, name := range names {
+		assets[i] = Asset{Name: name, URL: "https://example.com/" + name}
+	}
+	return assets
+}
+
+// Real-world release asset naming schemes, modeled after tools that ship
+// one binary per platform the way star.Install's targets do (ripgrep, fd,
+// bat, eza, lazygit, hyperfine, the GitHub CLI, kubectl, jq, Node.js, Go,
+// Neovim, starship, zoxide, fzf, k9s, Terraform, Helm, restic, minio's mc,
+// croc, Syncthing, Nomad, Consul, Vault, and a few deliberately awkward
+// ones) to make sure MatchAsset's scorer holds up against how the
+// ecosystem actually names things, not just the patterns it was written
+// against.
+var matchCases = []struct {
+	name     string
+	assets   []string
+	criteria Criteria
+	want     string
+	wantErr  bool
+}{
+	{
+		name: "ripgrep musl preferred on alpine",
+		assets: []string{
+			"ripgrep-13.0.0-x86_64-unknown-linux-musl.tar.gz",
+			"ripgrep-13.0.0-x86_64-unknown-linux-gnu.tar.gz",
+			"ripgrep-13.0.0-x86_64-apple-darwin.tar.gz",
+			"ripgrep-13.0.0-x86_64-pc-windows-msvc.zip",
+		},
+		criteria: Criteria{OS: "linux", Arch: "amd64", Libc: "musl"},
+		want:     "ripgrep-13.0.0-x86_64-unknown-linux-musl.tar.gz",
+	},
+	{
+		name: "ripgrep gnu preferred off alpine",
+		assets: []string{
+			"ripgrep-13.0.0-x86_64-unknown-linux-musl.tar.gz",
+			"ripgrep-13.0.0-x86_64-unknown-linux-gnu.tar.gz",
+		},
+		criteria: Criteria{OS: "linux", Arch: "amd64", Libc: "gnu"},
+		want:     "ripgrep-13.0.0-x86_64-unknown-linux-gnu.tar.gz",
+	},
+	{
+		name: "fd darwin amd64 among a full release",
+		assets: []string{
+			"fd-v8.7.0-x86_64-apple-darwin.tar.gz",
+			"fd-v8.7.0-x86_64-pc-windows-msvc.zip",
+			"fd-v8.7.0-aarch64-unknown-linux-gnu.tar.gz",
+		},
+		criteria: Criteria{OS: "darwin", Arch: "amd64"},
+		want:     "fd-v8.7.0-x86_64-apple-darwin.tar.gz",
+	},
+	{
+		name:     "bat linux gnu",
+		assets:   []string{"bat-v0.24.0-x86_64-unknown-linux-gnu.tar.gz", "bat-v0.24.0-x86_64-apple-darwin.tar.gz"},
+		criteria: Criteria{OS: "linux", Arch: "amd64"},
+		want:     "bat-v0.24.0-x86_64-unknown-linux-gnu.tar.gz",
+	},
+	{
+		name:     "eza underscore-joined name, hyphenated platform",
+		assets:   []string{"eza_x86_64-unknown-linux-gnu.tar.gz", "eza_aarch64-apple-darwin.tar.gz"},
+		criteria: Criteria{OS: "linux", Arch: "amd64"},
+		want:     "eza_x86_64-unknown-linux-gnu.tar.gz",
+	},
+	{
+		name: "lazygit capitalized OS/arch tokens",
+		assets: []string{
+			"lazygit_0.40.2_Linux_x86_64.tar.gz",
+			"lazygit_0.40.2_Darwin_arm64.tar.gz",
+			"lazygit_0.40.2_Windows_x86_64.zip",
+		},
+		criteria: Criteria{OS: "linux", Arch: "amd64"},
+		want:     "lazygit_0.40.2_Linux_x86_64.tar.gz",
+	},
+	{
+		name:     "hyperfine darwin",
+		assets:   []string{"hyperfine-v1.16.1-x86_64-apple-darwin.tar.gz", "hyperfine-v1.16.1-x86_64-unknown-linux-gnu.tar.gz"},
+		criteria: Criteria{OS: "darwin", Arch: "amd64"},
+		want:     "hyperfine-v1.16.1-x86_64-apple-darwin.tar.gz",
+	},
+	{
+		name: "gh cli with macOS capitalization",
+		assets: []string{
+			"gh_2.40.0_linux_amd64.tar.gz",
+			"gh_2.40.0_macOS_amd64.zip",
+			"gh_2.40.0_windows_amd64.zip",
+		},
+		criteria: Criteria{OS: "darwin", Arch: "amd64"},
+		want:     "gh_2.40.0_macOS_amd64.zip",
+	},
+	{
+		name:     "kubectl bare binary, no archive extension",
+		assets:   []string{"kubectl-linux-amd64", "kubectl-darwin-arm64", "kubectl-windows-amd64.exe"},
+		criteria: Criteria{OS: "linux", Arch: "amd64"},
+		want:     "kubectl-linux-amd64",
+	},
+	{
+		name:     "docker-compose hyphenated tool name plus bare binary",
+		assets:   []string{"docker-compose-linux-x86_64", "docker-compose-darwin-aarch64"},
+		criteria: Criteria{OS: "linux", Arch: "amd64"},
+		want:     "docker-compose-linux-x86_64",
+	},
+	{
+		name: "jq 1.7 style per-platform binaries",
+		assets: []string{
+			"jq-linux-amd64",
+			"jq-linux-arm64",
+			"jq-macos-amd64",
+			"jq-windows-amd64.exe",
+		},
+		criteria: Criteria{OS: "windows", Arch: "amd64"},
+		want:     "jq-windows-amd64.exe",
+	},
+	{
+		name: "node.js win/x64 aliasing",
+		assets: []string{
+			"node-v20.11.0-linux-x64.tar.gz",
+			"node-v20.11.0-darwin-arm64.tar.gz",
+			"node-v20.11.0-win-x64.zip",
+		},
+		criteria: Criteria{OS: "windows", Arch: "amd64"},
+		want:     "node-v20.11.0-win-x64.zip",
+	},
+	{
+		name: "go toolchain dot-joined version prefix",
+		assets: []string{
+			"go1.22.0.linux-amd64.tar.gz",
+			"go1.22.0.darwin-arm64.tar.gz",
+			"go1.22.0.windows-amd64.zip",
+		},
+		criteria: Criteria{OS: "linux", Arch: "amd64"},
+		want:     "go1.22.0.linux-amd64.tar.gz",
+	},
+	{
+		name: "neovim mixed underscore/hyphen arch token",
+		assets: []string{
+			"nvim-linux-x86_64.tar.gz",
+			"nvim-macos-arm64.tar.gz",
+			"nvim-win64.zip",
+		},
+		criteria: Criteria{OS: "darwin", Arch: "arm64"},
+		want:     "nvim-macos-arm64.tar.gz",
+	},
+	{
+		name: "starship musl/gnu/darwin triple",
+		assets: []string{
+			"starship-x86_64-unknown-linux-musl.tar.gz",
+			"starship-x86_64-unknown-linux-gnu.tar.gz",
+			"starship-aarch64-apple-darwin.tar.gz",
+		},
+		criteria: Criteria{OS: "darwin", Arch: "arm64"},
+		want:     "starship-aarch64-apple-darwin.tar.gz",
+	},
+	{
+		name:     "zoxide musl",
+		assets:   []string{"zoxide-0.9.4-x86_64-unknown-linux-musl.tar.gz", "zoxide-0.9.4-x86_64-unknown-linux-gnu.tar.gz"},
+		criteria: Criteria{OS: "linux", Arch: "amd64", Libc: "musl"},
+		want:     "zoxide-0.9.4-x86_64-unknown-linux-musl.tar.gz",
+	},
+	{
+		name: "fzf underscore before arch token",
+		assets: []string{
+			"fzf-0.46.0-linux_amd64.tar.gz",
+			"fzf-0.46.0-darwin_arm64.tar.gz",
+			"fzf-0.46.0-windows_amd64.zip",
+		},
+		criteria: Criteria{OS: "linux", Arch: "amd64"},
+		want:     "fzf-0.46.0-linux_amd64.tar.gz",
+	},
+	{
+		name:     "lazydocker capitalized Linux/x86_64",
+		assets:   []string{"lazydocker_0.23.1_Linux_x86_64.tar.gz", "lazydocker_0.23.1_Darwin_x86_64.tar.gz"},
+		criteria: Criteria{OS: "linux", Arch: "amd64"},
+		want:     "lazydocker_0.23.1_Linux_x86_64.tar.gz",
+	},
+	{
+		name: "dive prefers tar.gz over deb/rpm for the same platform",
+		assets: []string{
+			"dive_0.12.0_linux_amd64.deb",
+			"dive_0.12.0_linux_amd64.rpm",
+			"dive_0.12.0_linux_amd64.tar.gz",
+		},
+		criteria: Criteria{OS: "linux", Arch: "amd64"},
+		want:     "dive_0.12.0_linux_amd64.tar.gz",
+	},
+	{
+		name:     "k9s capitalized Darwin/arm64",
+		assets:   []string{"k9s_Linux_amd64.tar.gz", "k9s_Darwin_arm64.tar.gz", "k9s_Windows_amd64.zip"},
+		criteria: Criteria{OS: "darwin", Arch: "arm64"},
+		want:     "k9s_Darwin_arm64.tar.gz",
+	},
+	{
+		name:     "terraform zip-only release",
+		assets:   []string{"terraform_1.7.4_linux_amd64.zip", "terraform_1.7.4_darwin_arm64.zip"},
+		criteria: Criteria{OS: "linux", Arch: "amd64"},
+		want:     "terraform_1.7.4_linux_amd64.zip",
+	},
+	{
+		name:     "helm v-prefixed version before platform",
+		assets:   []string{"helm-v3.14.0-linux-amd64.tar.gz", "helm-v3.14.0-darwin-amd64.tar.gz"},
+		criteria: Criteria{OS: "linux", Arch: "amd64"},
+		want:     "helm-v3.14.0-linux-amd64.tar.gz",
+	},
+	{
+		name:     "restic bzip2, not an archive MatchAsset can extract",
+		assets:   []string{"restic_0.16.4_linux_amd64.bz2", "restic_0.16.4_darwin_amd64.bz2"},
+		criteria: Criteria{OS: "linux", Arch: "amd64"},
+		want:     "restic_0.16.4_linux_amd64.bz2",
+	},
+	{
+		name:     "minio mc, dotted tool name and bare binary",
+		assets:   []string{"mc.linux-amd64", "mc.darwin-arm64"},
+		criteria: Criteria{OS: "linux", Arch: "amd64"},
+		want:     "mc.linux-amd64",
+	},
+	{
+		name: "croc rejects a non-standard arch token in favor of x86_64",
+		assets: []string{
+			"croc_v9.6.7_Linux-64bit.tar.gz",
+			"croc_v9.6.7_Linux-x86_64.tar.gz",
+		},
+		criteria: Criteria{OS: "linux", Arch: "amd64"},
+		want:     "croc_v9.6.7_Linux-x86_64.tar.gz",
+	},
+	{
+		name:     "syncthing version suffix after arch",
+		assets:   []string{"syncthing-linux-amd64-v1.27.0.tar.gz", "syncthing-darwin-arm64-v1.27.0.tar.gz"},
+		criteria: Criteria{OS: "linux", Arch: "amd64"},
+		want:     "syncthing-linux-amd64-v1.27.0.tar.gz",
+	},
+	{
+		name:     "nomad/consul/vault-style HashiCorp naming",
+		assets:   []string{"nomad_1.7.2_linux_amd64.zip", "nomad_1.7.2_darwin_arm64.zip"},
+		criteria: Criteria{OS: "darwin", Arch: "arm64"},
+		want:     "nomad_1.7.2_darwin_arm64.zip",
+	},
+	{
+		name:     "windows amd64 not confused by a win32/x86 sibling",
+		assets:   []string{"app-windows-amd64.zip", "app-win32-x86.zip"},
+		criteria: Criteria{OS: "windows", Arch: "amd64"},
+		want:     "app-windows-amd64.zip",
+	},
+	{
+		name:     "osx alias for darwin",
+		assets:   []string{"app-osx-x64.tar.gz", "app-linux-x64.tar.gz"},
+		criteria: Criteria{OS: "darwin", Arch: "amd64"},
+		want:     "app-osx-x64.tar.gz",
+	},
+	{
+		name:     "AppImage and .deb lose to a real archive",
+		assets:   []string{"app-linux-x86_64.AppImage", "app-linux-amd64.deb", "app-linux-amd64.tar.gz"},
+		criteria: Criteria{OS: "linux", Arch: "amd64"},
+		want:     "app-linux-amd64.tar.gz",
+	},
+	{
+		name:     "no compatible asset for the platform",
+		assets:   []string{"app-windows-amd64.zip", "app-darwin-arm64.tar.gz"},
+		criteria: Criteria{OS: "linux", Arch: "amd64"},
+		wantErr:  true,
+	},
+	{
+		name:     "unrecognized GOARCH never panics, just reports no match",
+		assets:   []string{"app-linux-amd64.tar.gz"},
+		criteria: Criteria{OS: "linux", Arch: "riscv64"},
+		wantErr:  true,
+	},
+}
+
+func TestMatchAsset(t *testing.T) {
+	for _, tc := range matchCases {
+		t.Run(tc.name, func(t *testing.T) {
+			asset, err := MatchAsset(assetsNamed(tc.assets...), tc.criteria)
+			if tc.wantErr {
+				var noMatch *ErrNoCompatibleAsset
+				if !errors.As(err, &noMatch) {
+					t.Fatalf("MatchAsset() error = %v, want ErrNoCompatibleAsset", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MatchAsset() unexpected error: %v", err)
+			}
+			if asset.Name != tc.want {
+				t.Errorf("MatchAsset() = %q, want %q", asset.Name, tc.want)
+			}
+		})
+	}
+}
+
+// TestMatchAssetExcludesCompanionAssets makes sure checksums and
+// signatures are never picked as the install candidate, and never show up
+// in ErrNoCompatibleAsset's Tried list when nothing else matches.
+func TestMatchAssetExcludesCompanionAssets(t *testing.T) {
+	assets := assetsNamed(
+		"app-linux-amd64.tar.gz.sha256",
+		"app-linux-amd64.tar.gz.asc",
+		"SHA256SUMS",
+		"checksums.txt",
+		"app-darwin-arm64.tar.gz",
+	)
+
+	_, err := MatchAsset(assets, Criteria{OS: "linux", Arch: "amd64"})
+	var noMatch *ErrNoCompatibleAsset
+	if !errors.As(err, &noMatch) {
+		t.Fatalf("MatchAsset() error = %v, want ErrNoCompatibleAsset", err)
+	}
+	if len(noMatch.Tried) != 1 || noMatch.Tried[0] != "app-darwin-arm64.tar.gz" {
+		t.Errorf("Tried = %v, want only the non-companion asset", noMatch.Tried)
+	}
+}
@@ -0,0 +1,102 @@
+package star
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// installPlanItem is one package the resolver has decided needs installing,
+// in dependency-first order.
+type installPlanItem struct {
+	Package Package
+	Release releaseData
+}
+
+// resolvePlan walks pkg's manifest (and its dependencies' manifests,
+// recursively), returning every package that still needs installing to
+// satisfy pkg, in the order they must be installed. Already-installed
+// packages whose version satisfies the requirement are skipped. A
+// dependency cycle is reported as an error rather than looping forever.
+func resolvePlan(pkg Package, db *DB) ([]installPlanItem, error) {
+	return planInstall(pkg, db, map[string]bool{}, map[string]bool{})
+}
+
+func planInstall(pkg Package, db *DB, visiting, planned map[string]bool) ([]installPlanItem, error) {
+	key := strings.ToLower(pkg.User + "/" + pkg.Repo)
+	if visiting[key] {
+		return nil, fmt.Errorf("dependency cycle detected at %s/%s", pkg.User, pkg.Repo)
+	}
+	if planned[key] {
+		return nil, nil
+	}
+	visiting[key] = true
+	defer delete(visiting, key)
+
+	release, err := fetchRelease(pkg.User, pkg.Repo)
+	if err != nil {
+		return nil, fmt.Errorf("%s/%s: %w", pkg.User, pkg.Repo, err)
+	}
+
+	manifest, err := fetchManifest(release.Assets)
+	if err != nil {
+		return nil, fmt.Errorf("%s/%s: %w", pkg.User, pkg.Repo, err)
+	}
+
+	var items []installPlanItem
+	for _, req := range manifest.Requires {
+		depRepo, constraint, err := parseRequirement(req)
+		if err != nil {
+			return nil, fmt.Errorf("%s/%s: %w", pkg.User, pkg.Repo, err)
+		}
+
+		parts := strings.SplitN(depRepo, "/", 2)
+		depUser, depName := parts[0], parts[1]
+
+		if installed, ok, _ := db.Load(depUser, depName); ok {
+			if constraint == "" {
+				continue
+			}
+			if satisfied, err := versionSatisfies(installed.Version, constraint); err == nil && satisfied {
+				continue
+			}
+		}
+
+		depPkg := Package{User: depUser, Repo: depName, RequiredBy: pkg.User + "/" + pkg.Repo}
+		depItems, err := planInstall(depPkg, db, visiting, planned)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, depItems...)
+	}
+
+	planned[key] = true
+	items = append(items, installPlanItem{Package: pkg, Release: release})
+	return items, nil
+}
+
+// confirmPlan presents the resolved install plan to the user and asks for
+// confirmation before anything touches the filesystem. It's a package var
+// so tests (and future non-interactive front-ends) can swap in a
+// non-prompting implementation.
+var confirmPlan = func(items []installPlanItem) bool {
+	if len(items) == 0 {
+		return true
+	}
+
+	fmt.Println("The following packages will be installed:")
+	for _, item := range items {
+		suffix := ""
+		if item.Package.RequiredBy != "" {
+			suffix = fmt.Sprintf(" (required by %s)", item.Package.RequiredBy)
+		}
+		fmt.Printf("  %s/%s %s%s\n", item.Package.User, item.Package.Repo, item.Release.TagName, suffix)
+	}
+
+	fmt.Print("Continue? [y/N] ")
+	reader := bufio.NewReader(os.Stdin)
+	answer, _ := reader.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,115 @@
+package star
+
+import (
+	"strings"
+	"testing"
+)
+
+// withFakeManifests swaps fetchRelease/fetchManifest for fakes whose maps key
+// a package's lowercase "user/repo" to the release and manifest planInstall
+// should see for it, letting tests drive resolvePlan's
+// recursive dependency walk without ever touching the network.
+func withFakeManifests(t *testing.T, releases map[string]releaseData, manifests map[string]*Manifest) {
+	t.Helper()
+
+	origRelease, origManifest := fetchRelease, fetchManifest
+	t.Cleanup(func() {
+		fetchRelease = origRelease
+		fetchManifest = origManifest
+	})
+
+	fetchRelease = func(user, repo string) (releaseData, error) {
+		key := strings.ToLower(user + "/" + repo)
+		release, ok := releases[key]
+		if !ok {
+			t.Fatalf("unexpected fetchRelease(%q, %q)", user, repo)
+		}
+		return release, nil
+	}
+	fetchManifest = func(assets []Asset) (*Manifest, error) {
+		if len(assets) == 0 {
+			return &Manifest{}, nil
+		}
+		// The asset's URL doubles as the manifest lookup key, since the
+		// fake release data below always tags its star.json asset that way.
+		return manifests[assets[0].URL], nil
+	}
+}
+
+// releaseWithManifest builds a fake releaseData whose single asset points
+// fetchManifest (above) at the manifest registered for key.
+func releaseWithManifest(key string) releaseData {
+	return releaseData{TagName: "v1.0.0", Assets: []Asset{{Name: "star.json", URL: key}}}
+}
+
+func TestResolvePlanDetectsCycle(t *testing.T) {
+	withFakeManifests(t,
+		map[string]releaseData{
+			"a/b": releaseWithManifest("a/b"),
+			"a/c": releaseWithManifest("a/c"),
+		},
+		map[string]*Manifest{
+			"a/b": {Requires: []string{"a/c"}},
+			"a/c": {Requires: []string{"a/b"}},
+		},
+	)
+
+	db := &DB{Dir: t.TempDir()}
+	_, err := resolvePlan(Package{User: "a", Repo: "b"}, db)
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("resolvePlan() error = %v, want a dependency cycle error", err)
+	}
+}
+
+func TestResolvePlanSkipsSatisfiedVersion(t *testing.T) {
+	withFakeManifests(t,
+		map[string]releaseData{
+			"a/root": releaseWithManifest("a/root"),
+		},
+		map[string]*Manifest{
+			"a/root": {Requires: []string{"a/dep >= v1.2.0"}},
+		},
+	)
+
+	db := &DB{Dir: t.TempDir()}
+	if err := db.Save(Package{User: "a", Repo: "dep", Version: "v1.3.0"}); err != nil {
+		t.Fatalf("db.Save() error = %v", err)
+	}
+
+	plan, err := resolvePlan(Package{User: "a", Repo: "root"}, db)
+	if err != nil {
+		t.Fatalf("resolvePlan() error = %v", err)
+	}
+	if len(plan) != 1 || plan[0].Package.Repo != "root" {
+		t.Fatalf("resolvePlan() = %+v, want only the root package (dep already satisfies >= v1.2.0)", plan)
+	}
+}
+
+func TestResolvePlanIncludesUnsatisfiedVersion(t *testing.T) {
+	withFakeManifests(t,
+		map[string]releaseData{
+			"a/root": releaseWithManifest("a/root"),
+			"a/dep":  releaseWithManifest("a/dep"),
+		},
+		map[string]*Manifest{
+			"a/root": {Requires: []string{"a/dep >= v1.2.0"}},
+			"a/dep":  {},
+		},
+	)
+
+	db := &DB{Dir: t.TempDir()}
+	if err := db.Save(Package{User: "a", Repo: "dep", Version: "v1.0.0"}); err != nil {
+		t.Fatalf("db.Save() error = %v", err)
+	}
+
+	plan, err := resolvePlan(Package{User: "a", Repo: "root"}, db)
+	if err != nil {
+		t.Fatalf("resolvePlan() error = %v", err)
+	}
+	if len(plan) != 2 {
+		t.Fatalf("resolvePlan() = %+v, want dep and root (installed dep doesn't satisfy >= v1.2.0)", plan)
+	}
+	if plan[0].Package.Repo != "dep" || plan[1].Package.Repo != "root" {
+		t.Fatalf("resolvePlan() = %+v, want dep before root (dependency-first order)", plan)
+	}
+}
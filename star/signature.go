@@ -0,0 +1,132 @@
+package star
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/openpgp"        //nolint:staticcheck // no actively maintained replacement ships detached-signature verification
+	"golang.org/x/crypto/openpgp/armor"  //nolint:staticcheck
+	"golang.org/x/crypto/openpgp/packet" //nolint:staticcheck
+)
+
+// signatureSuffixes are the companion asset suffixes release workflows use
+// for detached signatures.
+var signatureSuffixes = []string{".asc", ".sig", ".minisig"}
+
+// starConfig mirrors the subset of config.json the star package itself
+// needs to read. It's kept separate from main's Config so the package has
+// no import dependency on the shell's config format.
+type starConfig struct {
+	TrustedKeys map[string]string `json:"trusted_keys"`
+	GithubToken string            `json:"github_token,omitempty"`
+}
+
+// loadStarConfig reads config.json's subset of fields the star package
+// cares about. It returns false if config.json is missing or unreadable,
+// which every caller treats the same as "nothing configured".
+func loadStarConfig() (starConfig, bool) {
+	data, err := os.ReadFile("config.json")
+	if err != nil {
+		return starConfig{}, false
+	}
+
+	var cfg starConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return starConfig{}, false
+	}
+	return cfg, true
+}
+
+// loadTrustedKey returns the armored public key the user has configured to
+// trust for repo (keyed as "user/repo" in config.json's trusted_keys map),
+// if any.
+func loadTrustedKey(repo string) (string, bool) {
+	cfg, ok := loadStarConfig()
+	if !ok {
+		return "", false
+	}
+
+	key, ok := cfg.TrustedKeys[repo]
+	return key, ok
+}
+
+// findSignatureAsset returns the release asset carrying a detached
+// signature for file, if one was published alongside it.
+func findSignatureAsset(assets []Asset, file string) (Asset, bool) {
+	lowerFile := strings.ToLower(file)
+	for _, suffix := range signatureSuffixes {
+		for _, asset := range assets {
+			if strings.ToLower(asset.Name) == lowerFile+suffix {
+				return asset, true
+			}
+		}
+	}
+	return Asset{}, false
+}
+
+// verifySignature checks that the detached signature in sigData was
+// produced by armoredKey over the contents of destPath. minisig-format
+// signatures aren't supported yet and are rejected explicitly rather than
+// silently accepted.
+func verifySignature(destPath string, sigData []byte, armoredKey string) error {
+	if bytes.HasPrefix(bytes.TrimSpace(sigData), []byte("untrusted comment:")) {
+		return fmt.Errorf("minisign signatures are not yet supported")
+	}
+
+	keyring, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armoredKey))
+	if err != nil {
+		return fmt.Errorf("failed to parse trusted key: %w", err)
+	}
+
+	file, err := os.Open(destPath)
+	if err != nil {
+		return fmt.Errorf("failed to open file for signature check: %w", err)
+	}
+	defer file.Close()
+
+	sigReader := bytes.NewReader(sigData)
+	if _, err := openpgp.CheckDetachedSignature(keyring, file, sigReader); err == nil {
+		return nil
+	}
+
+	// Fall back to armored signatures (`.asc`).
+	block, err := armor.Decode(bytes.NewReader(sigData))
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	reader := packet.NewReader(block.Body)
+	pkt, err := reader.Next()
+	if err != nil {
+		return fmt.Errorf("invalid signature packet: %w", err)
+	}
+	sig, ok := pkt.(*packet.Signature)
+	if !ok {
+		return fmt.Errorf("expected a signature packet, got %T", pkt)
+	}
+
+	if _, err := file.Seek(0, 0); err != nil {
+		return fmt.Errorf("failed to rewind file: %w", err)
+	}
+
+	for _, entity := range keyring {
+		if entity.PrimaryKey == nil {
+			continue
+		}
+		hash := sig.Hash.New()
+		if _, err := io.Copy(hash, file); err != nil {
+			return fmt.Errorf("failed to hash file: %w", err)
+		}
+		if err := entity.PrimaryKey.VerifySignature(hash, sig); err == nil {
+			return nil
+		}
+		if _, err := file.Seek(0, 0); err != nil {
+			return fmt.Errorf("failed to rewind file: %w", err)
+		}
+	}
+
+	return fmt.Errorf("signature verification failed: no trusted key matched")
+}
@@ -1,217 +1,286 @@
-package star
-
-import (
-	"encoding/json"
-	"errors"
-	"fmt"
-	"io"
-	"net/http"
-	"os"
-	"path/filepath"
-	"runtime"
-	"strings"
-)
-
-// Package represents a GitHub package to be managed.
-type Package struct {
-	User    string `json:"user"`
-	Repo    string `json:"repo"`
-	Version string `json:"version"`
-	File    string `json:"file"`
-}
-
-const starFile = "./stars/.stars"
-const installDir = "./stars"
-
-// Install installs the latest release of the specified package.
-func Install(pkg Package) error {
-	platform := getPlatform() // Get both OS and architecture.
-	if platform == "unknown-unknown" {
-		return errors.New("unsupported platform")
-	}
-
-	releaseURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/latest", pkg.User, pkg.Repo)
-	resp, err := http.Get(releaseURL)
-	if err != nil {
-		return fmt.Errorf("failed to fetch release: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to fetch release: status code %d", resp.StatusCode)
-	}
-
-	var releaseData struct {
-		Assets []struct {
-			Name string `json:"name"`
-			URL  string `json:"browser_download_url"`
-		} `json:"assets"`
-		TagName string `json:"tag_name"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&releaseData); err != nil {
-		return fmt.Errorf("failed to decode release data: %w", err)
-	}
-
-	// Match the correct asset based on platform (OS + architecture)
-	var downloadURL string
-	for _, asset := range releaseData.Assets {
-		if strings.Contains(strings.ToLower(asset.Name), platform) {
-			downloadURL = asset.URL
-			pkg.File = asset.Name
-			break
-		}
-	}
-
-	if downloadURL == "" {
-		return fmt.Errorf("no compatible release found for platform: %s", platform)
-	}
-
-	pkg.Version = releaseData.TagName
-	destPath := filepath.Join(installDir, pkg.File)
-	if err := os.MkdirAll(installDir, os.ModePerm); err != nil {
-		return fmt.Errorf("failed to create install directory: %w", err)
-	}
-
-	if err := downloadExecutable(downloadURL, destPath); err != nil {
-		return fmt.Errorf("failed to download executable: %w", err)
-	}
-
-	if err := updateStarsFile(pkg); err != nil {
-		return fmt.Errorf("failed to update stars file: %w", err)
-	}
-
-	return nil
-}
-
-// getPlatform determines the platform based on runtime environment.
-func getPlatform() string {
-	os := runtime.GOOS
-	arch := runtime.GOARCH
-
-	// Combine OS and architecture (e.g., "linux-amd64", "windows-arm64")
-	return fmt.Sprintf("%s-%s", os, arch)
-}
-
-// downloadExecutable downloads the executable file to the specified path.
-func downloadExecutable(url, destPath string) error {
-	resp, err := http.Get(url)
-	if err != nil {
-		return fmt.Errorf("failed to download file: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download file: status code %d", resp.StatusCode)
-	}
-
-	file, err := os.Create(destPath)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
-	}
-	defer file.Close()
-
-	_, err = io.Copy(file, resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
-
-	return nil
-}
-
-// updateStarsFile updates the .stars file with the installed package.
-func updateStarsFile(pkg Package) error {
-	packages, err := listInstalledPackages()
-	if err != nil && !errors.Is(err, os.ErrNotExist) {
-		return fmt.Errorf("failed to list installed packages: %w", err)
-	}
-
-	packages = append(packages, pkg)
-	return saveInstalledPackages(packages)
-}
-
-// listInstalledPackages lists all packages from the .stars file.
-func listInstalledPackages() ([]Package, error) {
-	file, err := os.Open(starFile)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	var packages []Package
-	if err := json.NewDecoder(file).Decode(&packages); err != nil {
-		return nil, fmt.Errorf("failed to decode .stars file: %w", err)
-	}
-
-	return packages, nil
-}
-
-// saveInstalledPackages saves the package list to the .stars file.
-func saveInstalledPackages(packages []Package) error {
-	file, err := os.Create(starFile)
-	if err != nil {
-		return fmt.Errorf("failed to create .stars file: %w", err)
-	}
-	defer file.Close()
-
-	if err := json.NewEncoder(file).Encode(packages); err != nil {
-		return fmt.Errorf("failed to write to .stars file: %w", err)
-	}
-
-	return nil
-}
-
-// ListInstalledStars lists all installed packages.
-func ListInstalledStars() ([]Package, error) {
-	return listInstalledPackages()
-}
-
-// Uninstall removes a package and updates the .stars file.
-// Uninstall removes a package and updates the .stars file.
-func Uninstall(pkg Package) error {
-	// Find the installed package by its file name
-	packages, err := listInstalledPackages()
-	if err != nil {
-		return fmt.Errorf("failed to list installed packages: %w", err)
-	}
-
-	// Locate the file for the package
-	var filePath string
-	for _, p := range packages {
-		if p.User == pkg.User && p.Repo == pkg.Repo {
-			filePath = filepath.Join(installDir, p.File)
-			break
-		}
-	}
-
-	if filePath == "" {
-		return fmt.Errorf("package %s/%s not found", pkg.User, pkg.Repo)
-	}
-
-	// Remove the executable file
-	if err := os.Remove(filePath); err != nil {
-		return fmt.Errorf("failed to remove executable: %w", err)
-	}
-
-	// Update the package list in .stars file
-	var updatedPackages []Package
-	for _, p := range packages {
-		if p.User != pkg.User || p.Repo != pkg.Repo {
-			updatedPackages = append(updatedPackages, p)
-		}
-	}
-
-	if err := saveInstalledPackages(updatedPackages); err != nil {
-		return fmt.Errorf("failed to update stars file: %w", err)
-	}
-
-	return nil
-}
-
-// Update updates an installed package.
-func Update(pkg Package) error {
-	if err := Uninstall(pkg); err != nil {
-		return fmt.Errorf("failed to uninstall package: %w", err)
-	}
-	return Install(pkg)
-}
+package star
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Package represents a GitHub package to be managed.
+type Package struct {
+	User    string `json:"user"`
+	Repo    string `json:"repo"`
+	Version string `json:"version"`
+	File    string `json:"file"`
+	// Entrypoint is a glob matching the binary to extract from an archive
+	// asset. If empty, the repo name is used.
+	Entrypoint string `json:"entrypoint,omitempty"`
+	// Mirrors are alternate URL prefixes tried, in order, if downloading
+	// from GitHub itself fails.
+	Mirrors []string `json:"mirrors,omitempty"`
+	// RequiredBy is the "user/repo" that pulled this package in as a
+	// dependency. Empty for packages the user installed directly.
+	RequiredBy string `json:"required_by,omitempty"`
+}
+
+// Asset is a single downloadable file attached to a GitHub release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// releaseData is the subset of the GitHub "get latest release" response
+// Install cares about.
+type releaseData struct {
+	Assets  []Asset `json:"assets"`
+	TagName string  `json:"tag_name"`
+}
+
+const installDir = "./stars"
+
+// Install installs the latest release of the specified package, along with
+// any dependencies declared in its star.json/star.yaml manifest. The full
+// install plan is resolved and presented to the user before anything is
+// written to disk.
+func Install(pkg Package) error {
+	if getPlatform() == "unknown-unknown" {
+		return errors.New("unsupported platform")
+	}
+
+	plan, err := resolvePlan(pkg, defaultDB)
+	if err != nil {
+		return fmt.Errorf("failed to resolve dependencies: %w", err)
+	}
+
+	if !confirmPlan(plan) {
+		return errors.New("installation cancelled")
+	}
+
+	unlock, err := defaultDB.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for _, item := range plan {
+		installed, err := installSingle(item.Package, item.Release)
+		if err != nil {
+			return fmt.Errorf("failed to install %s/%s: %w", item.Package.User, item.Package.Repo, err)
+		}
+		if err := defaultDB.Save(installed); err != nil {
+			return fmt.Errorf("failed to record %s/%s: %w", item.Package.User, item.Package.Repo, err)
+		}
+	}
+
+	return nil
+}
+
+// fetchRelease fetches the latest release metadata for user/repo, going
+// through defaultGithubClient so it's authenticated, cached, and rate-limit
+// aware. It's a package var, like newDownloader and confirmPlan, so tests
+// can substitute a fake without hitting the network.
+var fetchRelease = func(user, repo string) (releaseData, error) {
+	releaseURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/latest", user, repo)
+	body, err := defaultGithubClient.Get(releaseURL)
+	if err != nil {
+		return releaseData{}, fmt.Errorf("failed to fetch release: %w", err)
+	}
+
+	var release releaseData
+	if err := json.Unmarshal(body, &release); err != nil {
+		return releaseData{}, fmt.Errorf("failed to decode release data: %w", err)
+	}
+	return release, nil
+}
+
+// installSingle downloads, verifies, and (if needed) extracts the release
+// asset matching pkg's platform, returning the Package record to save to
+// the database. It performs no dependency resolution of its own.
+func installSingle(pkg Package, release releaseData) (Package, error) {
+	asset, err := MatchAsset(release.Assets, currentCriteria())
+	if err != nil {
+		return Package{}, err
+	}
+	downloadURL, assetName := asset.URL, asset.Name
+
+	pkg.Version = release.TagName
+	if err := os.MkdirAll(installDir, os.ModePerm); err != nil {
+		return Package{}, fmt.Errorf("failed to create install directory: %w", err)
+	}
+	destPath := filepath.Join(installDir, assetName)
+
+	var verify func(path string) error
+	if checksumAsset, ok := findChecksumAsset(release.Assets, assetName); ok {
+		verify = func(path string) error {
+			return verifyChecksum(checksumAsset, assetName, path)
+		}
+	}
+
+	if err := downloadExecutable(downloadURL, destPath, pkg.Mirrors, verify); err != nil {
+		return Package{}, fmt.Errorf("failed to download executable: %w", err)
+	}
+
+	repo := pkg.User + "/" + pkg.Repo
+	if sigAsset, ok := findSignatureAsset(release.Assets, assetName); ok {
+		if key, ok := loadTrustedKey(repo); ok {
+			sigResp, err := http.Get(sigAsset.URL)
+			if err != nil {
+				os.Remove(destPath)
+				return Package{}, fmt.Errorf("failed to fetch signature: %w", err)
+			}
+			sigData, err := io.ReadAll(sigResp.Body)
+			sigResp.Body.Close()
+			if err != nil {
+				os.Remove(destPath)
+				return Package{}, fmt.Errorf("failed to read signature: %w", err)
+			}
+			if err := verifySignature(destPath, sigData, key); err != nil {
+				os.Remove(destPath)
+				return Package{}, fmt.Errorf("signature verification failed: %w", err)
+			}
+		}
+	}
+
+	pkg.File = assetName
+	if isArchive(assetName) {
+		extractedPath, err := extractBinary(destPath, pkg.Repo, pkg.Entrypoint)
+		if err != nil {
+			return Package{}, fmt.Errorf("failed to extract archive: %w", err)
+		}
+		pkg.File = extractedPath
+	} else if runtime.GOOS != "windows" {
+		if err := os.Chmod(destPath, 0755); err != nil {
+			return Package{}, fmt.Errorf("failed to mark executable: %w", err)
+		}
+	}
+
+	return pkg, nil
+}
+
+// getPlatform determines the platform based on runtime environment.
+func getPlatform() string {
+	os := runtime.GOOS
+	arch := runtime.GOARCH
+
+	// Combine OS and architecture (e.g., "linux-amd64", "windows-arm64")
+	return fmt.Sprintf("%s-%s", os, arch)
+}
+
+// downloadExecutable downloads the executable file to the specified path,
+// showing progress, resuming partial downloads, and falling back across
+// mirrors. If verify is non-nil, it's run against the downloaded file before
+// destPath is created; see HTTPDownloader for the details.
+func downloadExecutable(url, destPath string, mirrors []string, verify func(path string) error) error {
+	all := append(append([]string{}, mirrors...), githubMirrorsFromEnv()...)
+	return newDownloader(all).Download(url, destPath, verify)
+}
+
+// ListInstalledStars lists all installed packages.
+func ListInstalledStars() ([]Package, error) {
+	return defaultDB.List()
+}
+
+// Uninstall removes an installed package and its database record.
+func Uninstall(pkg Package) error {
+	unlock, err := defaultDB.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	installed, ok, err := defaultDB.Load(pkg.User, pkg.Repo)
+	if err != nil {
+		return fmt.Errorf("failed to look up installed package: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("package %s/%s not found", pkg.User, pkg.Repo)
+	}
+
+	if err := os.Remove(filepath.Join(installDir, installed.File)); err != nil {
+		return fmt.Errorf("failed to remove executable: %w", err)
+	}
+
+	if err := defaultDB.Delete(pkg.User, pkg.Repo); err != nil {
+		return fmt.Errorf("failed to update package database: %w", err)
+	}
+
+	return nil
+}
+
+// Update reinstalls a package at its latest version.
+func Update(pkg Package) error {
+	if err := Uninstall(pkg); err != nil {
+		return fmt.Errorf("failed to uninstall package: %w", err)
+	}
+	return Install(pkg)
+}
+
+// UpdateInfo describes an installed package with a newer release available.
+type UpdateInfo struct {
+	Package       Package
+	LatestVersion string
+}
+
+// CheckUpdates compares every installed package's recorded version against
+// its latest GitHub release, returning those that are out of date.
+func CheckUpdates() ([]UpdateInfo, error) {
+	installed, err := defaultDB.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed packages: %w", err)
+	}
+
+	var updates []UpdateInfo
+	for _, pkg := range installed {
+		release, err := fetchRelease(pkg.User, pkg.Repo)
+		if err != nil {
+			return nil, fmt.Errorf("%s/%s: %w", pkg.User, pkg.Repo, err)
+		}
+		if compareVersions(release.TagName, pkg.Version) > 0 {
+			updates = append(updates, UpdateInfo{Package: pkg, LatestVersion: release.TagName})
+		}
+	}
+	return updates, nil
+}
+
+// Why returns the dependency chain that caused user/repo to be installed:
+// the package itself, followed by each package that required it, ending
+// with the package the user asked for directly. It returns a single-element
+// chain if the package was installed directly.
+func Why(user, repo string) ([]Package, error) {
+	pkg, ok, err := defaultDB.Load(user, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up package: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("package %s/%s is not installed", user, repo)
+	}
+
+	chain := []Package{pkg}
+	seen := map[string]bool{strings.ToLower(user + "/" + repo): true}
+	for pkg.RequiredBy != "" {
+		if seen[strings.ToLower(pkg.RequiredBy)] {
+			return nil, fmt.Errorf("cycle detected while tracing dependency chain for %s/%s", user, repo)
+		}
+		parts := strings.SplitN(pkg.RequiredBy, "/", 2)
+		if len(parts) != 2 {
+			break
+		}
+		next, ok, err := defaultDB.Load(parts[0], parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up package: %w", err)
+		}
+		if !ok {
+			break
+		}
+		chain = append(chain, next)
+		seen[strings.ToLower(pkg.RequiredBy)] = true
+		pkg = next
+	}
+	return chain, nil
+}
@@ -0,0 +1,76 @@
+package star
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseVersion splits a tag like "v1.2.3" into its numeric components.
+// Non-numeric or missing components are treated as 0, so "v1.2" and
+// "v1.2.0" compare equal.
+func parseVersion(tag string) [3]int {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "v")
+	parts := strings.SplitN(tag, ".", 3)
+
+	var v [3]int
+	for i := 0; i < len(parts) && i < 3; i++ {
+		// Strip any pre-release/build suffix (e.g. "3-rc1").
+		numeric := strings.FieldsFunc(parts[i], func(r rune) bool { return r < '0' || r > '9' })
+		if len(numeric) == 0 {
+			continue
+		}
+		n, err := strconv.Atoi(numeric[0])
+		if err != nil {
+			continue
+		}
+		v[i] = n
+	}
+	return v
+}
+
+// compareVersions returns -1, 0, or 1 depending on whether a is less than,
+// equal to, or greater than b.
+func compareVersions(a, b string) int {
+	va, vb := parseVersion(a), parseVersion(b)
+	for i := 0; i < 3; i++ {
+		if va[i] != vb[i] {
+			if va[i] < vb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionSatisfies reports whether version satisfies a constraint of the
+// form "<op> <version>" (e.g. ">= v1.2.0"). A bare version with no
+// operator is treated as "==".
+func versionSatisfies(version, constraint string) (bool, error) {
+	constraint = strings.TrimSpace(constraint)
+	op := "=="
+	for _, candidate := range []string{">=", "<=", ">", "<", "==", "="} {
+		if strings.HasPrefix(constraint, candidate) {
+			op = candidate
+			constraint = strings.TrimSpace(strings.TrimPrefix(constraint, candidate))
+			break
+		}
+	}
+
+	cmp := compareVersions(version, constraint)
+	switch op {
+	case ">=":
+		return cmp >= 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	case "<":
+		return cmp < 0, nil
+	case "==", "=":
+		return cmp == 0, nil
+	default:
+		return false, fmt.Errorf("unsupported constraint operator: %s", op)
+	}
+}
@@ -1,9 +1,10 @@
 package main
 
 import (
-	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -11,7 +12,7 @@ import (
 	"strings"
 	"time"
 
-	"starshell/star"
+	"github.com/chzyer/readline"
 )
 
 // ANSI color codes
@@ -145,18 +146,19 @@ func getFileColor(file os.DirEntry) string {
 	}
 }
 
-// Custom ls command
-func customLs() {
+// Custom ls command. It writes to w rather than directly to os.Stdout so it
+// behaves when run inside a pipeline (e.g. `ls | wc -l`).
+func customLs(w io.Writer) {
 	dir := getCurrentDirectory()
 	files, err := os.ReadDir(dir)
 	if err != nil {
-		fmt.Println(BoldRed + "[ERROR] Error reading directory: " + err.Error() + Reset)
+		fmt.Fprintln(w, BoldRed+"[ERROR] Error reading directory: "+err.Error()+Reset)
 		return
 	}
 
 	for _, file := range files {
 		color := getFileColor(file)
-		fmt.Println(color + file.Name() + Reset)
+		fmt.Fprintln(w, color+file.Name()+Reset)
 	}
 }
 
@@ -205,131 +207,60 @@ func getHomeDirectory() string {
 	return home
 }
 
-func executeCommand(input string) {
-	args := strings.Fields(input)
-	if len(args) == 0 {
+// Main function
+func main() {
+	// Load configuration
+	err := LoadConfig("config.json")
+	if err != nil {
+		fmt.Println(BoldRed + "[ERROR] Failed to load config: " + err.Error() + Reset)
 		return
 	}
 
-	// Check for alias
-	if alias, exists := config.Aliases[args[0]]; exists {
-		args = append(strings.Fields(alias), args[1:]...)
-	}
-
-	switch args[0] {
-	case "cd":
-		dir := "."
-		if len(args) > 1 {
-			dir = args[1]
-		}
-		if dir == "~" {
-			dir = getHomeDirectory()
-		}
-
-		err := changeDirectory(dir)
-		if err != nil {
-			fmt.Println(BoldRed + err.Error() + Reset)
-		}
-		return
-
-	case "ls", "dir":
-		customLs()
-		return
-
-	case "clear", "cls":
-		clearScreen()
-		return
-
-	case "star":
-		if len(args) < 2 {
-			fmt.Println(BoldRed + "[ERROR] Missing subcommand. Use 'star install user/repo' or other commands." + Reset)
-			return
-		}
-
-		switch args[1] {
-		case "install":
-			if len(args) < 3 {
-				fmt.Println(BoldRed + "[ERROR] Missing repository argument. Use 'star install user/repo'." + Reset)
-				return
-			}
-			repo := args[2]
-			fmt.Println(DefaultGreen + "Installing " + repo + "..." + Reset)
-			err := star.Install(star.Package{User: strings.Split(repo, "/")[0], Repo: strings.Split(repo, "/")[1]})
-			if err != nil {
-				fmt.Println(BoldRed + "[ERROR] Installation failed: " + err.Error() + Reset)
-			} else {
-				fmt.Println(DefaultGreen + "[SUCCESS] " + repo + " installed successfully!" + Reset)
-			}
-
-		case "list":
-			installed, err := star.ListInstalledStars()
-			if err != nil {
-				fmt.Println(BoldRed + "[ERROR] Could not list installed packages: " + err.Error() + Reset)
-				return
-			}
-			for _, pkg := range installed {
-				fmt.Printf(DefaultGreen+"- %s/%s@%s\n"+Reset, pkg.User, pkg.Repo, pkg.Version)
-			}
-
-		case "uninstall":
-			if len(args) < 3 {
-				fmt.Println(BoldRed + "[ERROR] Missing repository argument. Use 'star uninstall user/repo'." + Reset)
-				return
-			}
-			repo := args[2]
-			fmt.Println(DefaultRed + "Uninstalling " + repo + "..." + Reset)
-			err := star.Uninstall(star.Package{User: strings.Split(repo, "/")[0], Repo: strings.Split(repo, "/")[1]})
-			if err != nil {
-				fmt.Println(BoldRed + "[ERROR] Uninstallation failed: " + err.Error() + Reset)
-			} else {
-				fmt.Println(DefaultGreen + "[SUCCESS] " + repo + " uninstalled successfully!" + Reset)
-			}
-
-		default:
-			fmt.Println(BoldRed + "[ERROR] Unknown 'star' subcommand." + Reset)
-		}
+	runner, err := newShellRunner()
+	if err != nil {
+		fmt.Println(BoldRed + "[ERROR] Failed to start shell: " + err.Error() + Reset)
 		return
 	}
 
-	// External commands
-	cmd := exec.Command(args[0], args[1:]...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-	err := cmd.Run()
-	if err != nil {
-		fmt.Println(BoldRed+"[ERROR] "+Reset, err)
+	if err := loadRCFile(runner); err != nil {
+		fmt.Println(BoldRed + "[ERROR] " + err.Error() + Reset)
 	}
-}
 
-// Main function
-func main() {
-	// Load configuration
-	err := LoadConfig("config.json")
+	watchJobSignals()
+
+	editor, err := newLineEditor()
 	if err != nil {
-		fmt.Println(BoldRed + "[ERROR] Failed to load config: " + err.Error() + Reset)
+		fmt.Println(BoldRed + "[ERROR] Failed to start line editor: " + err.Error() + Reset)
 		return
 	}
+	defer editor.Close()
 
-	reader := bufio.NewReader(os.Stdin)
 	for {
-		// Display the prompt
-		fmt.Print(generatePrompt())
+		editor.SetPrompt(generatePrompt())
 
-		// Read user input
-		input, err := reader.ReadString('\n')
+		input, err := editor.Readline()
+		if errors.Is(err, readline.ErrInterrupt) {
+			continue
+		}
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
 			fmt.Fprintln(os.Stderr, BoldRed+"[ERROR] "+Reset, err)
 			continue
 		}
+
 		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
 
 		// Exit on "exit"
 		if input == "exit" {
 			break
 		}
 
-		// Execute the command
-		executeCommand(input)
+		saveLine(editor, input)
+		runLine(runner, input)
 	}
 }